@@ -0,0 +1,126 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncOptions struct
+type AsyncOptions struct {
+	// BufferSize is the number of log entries that can be queued before the buffer is full.
+	BufferSize int
+	// DropWhenFull discards entries when the buffer is full instead of blocking the caller
+	// until the background writer catches up. Dropped entries are counted and reported by
+	// the close function.
+	DropWhenFull bool
+}
+
+// NewAsync creates a new logger that hands formatted entries to a background goroutine
+// instead of writing them to o on the caller's goroutine. Callers block when the buffer is
+// full. The returned function flushes the remaining entries and must be called at shutdown,
+// Logger.Close calls it as well.
+func NewAsync(l Level, o io.Writer, bufferSize int) (Logger, func() error) {
+	return NewAsyncWithOptions(l, o, AsyncOptions{
+		BufferSize: bufferSize,
+	})
+}
+
+// NewAsyncWithOptions creates a new asynchronous logger, see NewAsync.
+func NewAsyncWithOptions(l Level, o io.Writer, options AsyncOptions) (Logger, func() error) {
+	w := newAsyncWriter(o, options)
+	logger := New(l, w)
+	logger.core.addCloser(w.Close)
+
+	return logger, w.Close
+}
+
+// asyncWriter queues writes on a buffered channel consumed by a single goroutine.
+type asyncWriter struct {
+	out          io.Writer
+	entries      chan []byte
+	done         chan struct{}
+	dropWhenFull bool
+	dropped      uint64
+
+	mu     sync.RWMutex
+	closed bool
+	err    error
+}
+
+func newAsyncWriter(o io.Writer, options AsyncOptions) *asyncWriter {
+	bufferSize := options.BufferSize
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+
+	w := &asyncWriter{
+		out:          o,
+		entries:      make(chan []byte, bufferSize),
+		done:         make(chan struct{}),
+		dropWhenFull: options.DropWhenFull,
+	}
+	go w.run()
+
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for entry := range w.entries {
+		if _, err := w.out.Write(entry); err != nil && w.err == nil {
+			w.err = err
+		}
+	}
+}
+
+// Write queues a copy of p, the caller's buffer is reused by logrus once Write returns.
+// Once the writer is closed and drained, writes go straight to the underlying writer.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		<-w.done
+		return w.out.Write(p)
+	}
+
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	if w.dropWhenFull {
+		select {
+		case w.entries <- entry:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+		return len(p), nil
+	}
+
+	w.entries <- entry
+	return len(p), nil
+}
+
+// Close flushes the queued entries and stops the background goroutine. It returns the first
+// error from the underlying writer, or an error reporting how many entries were dropped.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.entries)
+	w.mu.Unlock()
+
+	<-w.done
+
+	if w.err != nil {
+		return w.err
+	}
+	if dropped := atomic.LoadUint64(&w.dropped); dropped > 0 {
+		return fmt.Errorf("golog: dropped %d log entries", dropped)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package golog
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxDecodedBodyLogBytes bounds how much of a compressed body is decompressed for logging,
+// so that a small compressed payload can't expand into an arbitrarily large one. A lower
+// MaxBodyLogBytes bounds it further.
+const maxDecodedBodyLogBytes = 1 << 20
+
+// bodyKind tells how a body is logged, based on its content type.
+type bodyKind int
+
+const (
+	// bodyKindJSON bodies are parsed as JSON. Bodies without a content type are treated as
+	// JSON as well, falling back to the raw string if they don't parse.
+	bodyKindJSON bodyKind = iota
+	// bodyKindForm bodies are parsed as URL-encoded forms.
+	bodyKindForm
+	// bodyKindText bodies are logged as strings.
+	bodyKindText
+	// bodyKindBinary bodies are not logged, only their size is.
+	bodyKindBinary
+)
+
+func bodyKindOf(contentType string) bodyKind {
+	if contentType == "" {
+		return bodyKindJSON
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return bodyKindBinary
+	}
+
+	switch {
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return bodyKindJSON
+	case mediaType == "application/x-www-form-urlencoded":
+		return bodyKindForm
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/xml", strings.HasSuffix(mediaType, "+xml"):
+		return bodyKindText
+	default:
+		return bodyKindBinary
+	}
+}
+
+// parseBody decodes a raw request or response body for logging according to its headers,
+// decompressing at most maxBytes of it if it is positive. The returned logger carries the
+// bodyError or bodySize fields when the body can't be logged.
+func parseBody(logger Logger, header http.Header, buf []byte, maxBytes int64) (interface{}, Logger) {
+	buf, err := decodeBody(header.Get("Content-Encoding"), buf, maxBytes)
+	if err != nil {
+		return nil, logger.WithFields(map[string]interface{}{"bodyError": err})
+	}
+
+	var body interface{}
+	switch bodyKindOf(header.Get("Content-Type")) {
+	case bodyKindJSON:
+		if body, err = parseJSONBody(buf); err != nil {
+			body = string(buf)
+			logger = logger.WithFields(map[string]interface{}{"bodyError": err})
+		}
+	case bodyKindForm:
+		if body, err = parseFormBody(buf); err != nil {
+			body = string(buf)
+			logger = logger.WithFields(map[string]interface{}{"bodyError": err})
+		}
+	case bodyKindText:
+		body = string(buf)
+	default:
+		logger = logger.WithFields(map[string]interface{}{"bodySize": len(buf)})
+	}
+
+	return body, logger
+}
+
+// parseJSONBody parses a JSON body, keeping numbers as json.Number so that large integers
+// such as IDs are logged without losing precision.
+func parseJSONBody(buf []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+
+	var body interface{}
+	if err := decoder.Decode(&body); err == io.EOF {
+		return nil, errors.New("unexpected end of JSON input")
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+
+	return body, nil
+}
+
+// parseFormBody parses a URL-encoded body into a map, parameters given several times are
+// kept as a list of values.
+func parseFormBody(buf []byte) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(string(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	return flattenValues(values), nil
+}
+
+func flattenValues(values map[string][]string) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if len(v) == 1 {
+			m[k] = v[0]
+		} else {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+// skippedBodyFields returns the fields logged in place of a body left out for its size or
+// type: its declared length, if any.
+func skippedBodyFields(contentLength int64) map[string]interface{} {
+	if contentLength < 0 {
+		return nil
+	}
+
+	return map[string]interface{}{"contentLength": contentLength}
+}
+
+// truncatedBodyFields returns the fields logged in place of a body whose reading stopped
+// before its end, after read bytes, along with its declared length if any.
+func truncatedBodyFields(contentLength int64, read int) map[string]interface{} {
+	fields := map[string]interface{}{
+		"bodyTruncated": true,
+		"bodyBytesRead": read,
+	}
+	if contentLength >= 0 {
+		fields["contentLength"] = contentLength
+	}
+
+	return fields
+}
+
+// readLimitedBody reads body for logging, at most maxBytes of it when maxBytes is positive.
+// It returns what was read and a body reading the original one from its start, the bytes
+// read followed by the rest, so that a body too large to be logged is still streamed in full.
+// truncated is set when the body is longer than maxBytes, and err is the read error.
+func readLimitedBody(body io.ReadCloser, maxBytes int64) (buf []byte, restored io.ReadCloser, truncated bool, err error) {
+	r := io.Reader(body)
+	if maxBytes > 0 {
+		r = io.LimitReader(body, maxBytes+1)
+	}
+	buf, err = ioutil.ReadAll(r)
+
+	restored = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), body), body}
+
+	return buf, restored, maxBytes > 0 && int64(len(buf)) > maxBytes, err
+}
+
+// decodeBody decompresses a body sent with the gzip or deflate content encoding, failing if
+// it decompresses to more than maxBytes, or maxDecodedBodyLogBytes if it is lower or maxBytes
+// isn't positive. Other encodings are returned as is.
+func decodeBody(contentEncoding string, buf []byte, maxBytes int64) ([]byte, error) {
+	var reader io.Reader
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "deflate":
+		// deflate is meant to be zlib wrapped, but some clients send raw deflate data
+		zlibReader, err := zlib.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(buf))
+		} else {
+			reader = zlibReader
+		}
+	default:
+		return buf, nil
+	}
+
+	limit := int64(maxDecodedBodyLogBytes)
+	if maxBytes > 0 && maxBytes < limit {
+		limit = maxBytes
+	}
+	decoded, err := ioutil.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decoded)) > limit {
+		return nil, fmt.Errorf("decompressed body exceeds %d bytes", limit)
+	}
+
+	return decoded, nil
+}
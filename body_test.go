@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gzipped returns s compressed with gzip.
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeBodyLimit(t *testing.T) {
+	body := gzipped(t, strings.Repeat("a", 8<<10))
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		wantErr  bool
+	}{
+		{name: "no limit", maxBytes: 0},
+		{name: "larger limit", maxBytes: 16 << 10},
+		{name: "exact limit", maxBytes: 8 << 10},
+		{name: "smaller limit", maxBytes: 4 << 10, wantErr: true},
+		{name: "above the decoded cap", maxBytes: 4 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeBody("gzip", body, tt.maxBytes)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exceeds 4096 bytes") {
+					t.Errorf("decodeBody() error = %v, want the limit exceeded", err)
+				}
+				return
+			}
+			if err != nil || len(decoded) != 8<<10 {
+				t.Errorf("decodeBody() = %d bytes, %v, want the whole body", len(decoded), err)
+			}
+		})
+	}
+}
+
+func TestDecodeBodyCap(t *testing.T) {
+	body := gzipped(t, strings.Repeat("a", maxDecodedBodyLogBytes+1))
+
+	for _, maxBytes := range []int64{0, 4 << 20} {
+		if _, err := decodeBody("gzip", body, maxBytes); err == nil {
+			t.Errorf("decodeBody(maxBytes %d) succeeded, want the %d bytes cap exceeded", maxBytes, maxDecodedBodyLogBytes)
+		}
+	}
+}
+
+func TestMiddlewareCompressedBodyLimit(t *testing.T) {
+	handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), MiddlewareOptions{MaxBodyLogBytes: 4096})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipped(t, strings.Repeat("a", 8<<10))))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Content-Encoding", "gzip")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := requestEntry(t, observer)
+	if entry.Fields["bodyError"] == nil {
+		t.Errorf("bodyError missing, want the decompressed body over MaxBodyLogBytes rejected")
+	}
+	if body := entry.Fields["requestBody"]; body != nil && body != "" {
+		t.Errorf("requestBody = %.20v..., want none", body)
+	}
+}
@@ -0,0 +1,52 @@
+package golog
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// CallerKey holds the Caller of the entries of a logger created with WithCaller.
+const CallerKey = "caller"
+
+// Caller is the source location an entry was logged from.
+type Caller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
+
+func (c Caller) String() string {
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// WithCaller adds the location of the code logging each entry under CallerKey. With
+// FormatGCP it is logged as the entry's source location instead.
+func WithCaller() Option {
+	return func(o *options) {
+		o.reportCaller = true
+	}
+}
+
+// packagePrefix is the prefix of the functions of this package, skipped to find the caller.
+var packagePrefix = reflect.TypeOf(Logger{}).PkgPath() + "."
+
+// caller returns the first frame of the stack outside this package, log/slog, whose handler
+// is implemented here, and log, whose loggers StdLogger returns.
+func caller() Caller {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) &&
+			!strings.HasPrefix(frame.Function, "log/slog.") &&
+			!strings.HasPrefix(frame.Function, "log.") {
+			return Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+		}
+		if !more {
+			return Caller{}
+		}
+	}
+}
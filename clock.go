@@ -0,0 +1,45 @@
+package golog
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Clock is the source of the current time used for the entries' timestamps and the
+// durations logged by the middlewares and interceptors, see WithClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock makes the logger read the time from clock instead of the system clock, so that
+// tests can produce stable timestamps and durations, see logtest.FakeClock.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// now returns the current time according to the logger's clock.
+func (l Logger) now() time.Time {
+	if l.core != nil && l.core.clock != nil {
+		return l.core.clock.Now()
+	}
+
+	return time.Now()
+}
+
+// since returns the time elapsed since t according to the logger's clock.
+func (l Logger) since(t time.Time) time.Duration {
+	return l.now().Sub(t)
+}
+
+// timestamped returns the entry stamped with the logger's clock, logrus reads the system
+// clock for entries without a time.
+func (l Logger) timestamped(entry *logrus.Entry) *logrus.Entry {
+	if l.core == nil || l.core.clock == nil || !entry.Time.IsZero() {
+		return entry
+	}
+
+	return entry.WithTime(l.core.clock.Now())
+}
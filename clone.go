@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SetLevel changes the level of the underlying logger, which is shared by this logger and
+// every logger derived from the same New call. Use Clone first to change it for this logger
+// only. The level is stored atomically, so it can be changed at runtime while other
+// goroutines are logging.
+func (l Logger) SetLevel(level Level) {
+	l.logger.Logger.SetLevel(level.toLogrusLevel())
+}
+
+// Level returns the current level of the logger, see SetLevel.
+func (l Logger) Level() Level {
+	return fromLogrusLevel(l.logger.Logger.GetLevel())
+}
+
+// SetOutput changes the writer of the underlying logger, which is shared like the level, see
+// SetLevel. It doesn't affect the sinks of NewWithSinks.
+func (l Logger) SetOutput(o io.Writer) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	replaced := map[outputHook]outputHook{}
+	hooks := copyHooks(l.logger.Logger.Hooks)
+	for _, levelHooks := range hooks {
+		for i, hook := range levelHooks {
+			output, ok := hook.(writerOutput)
+			if !ok {
+				continue
+			}
+			if replaced[output] == nil {
+				replaced[output] = output.withWriter(o)
+			}
+			levelHooks[i] = replaced[output]
+		}
+	}
+	l.logger.Logger.ReplaceHooks(hooks)
+}
+
+// Clone returns a copy of the logger, fields included, backed by a new underlying logger
+// with the same formatter, level, output and hooks. Calling SetLevel, SetOutput or AddHook
+// on the clone doesn't affect the original logger and vice versa.
+func (l Logger) Clone() Logger {
+	parent := l.logger.Logger
+	l.core.mu.Lock()
+	hooks := copyHooks(parent.Hooks)
+	l.core.mu.Unlock()
+
+	logger := logrus.New()
+	logger.Out = parent.Out
+	logger.Formatter = parent.Formatter
+	logger.Hooks = hooks
+	logger.ReportCaller = parent.ReportCaller
+	logger.SetLevel(parent.GetLevel())
+
+	l.logger = logrus.NewEntry(logger).
+		WithFields(l.logger.Data).
+		WithContext(l.logger.Context).
+		WithTime(l.logger.Time)
+
+	return l
+}
+
+// AtLevel returns a copy of the logger emitting the entries at the given level and above,
+// regardless of the level of the logger it derives from, for instance to get debug details
+// of a single operation. It is a Clone with its own level, the original logger is unaffected.
+func (l Logger) AtLevel(level Level) Logger {
+	l = l.Clone()
+	l.SetLevel(level)
+
+	return l
+}
@@ -0,0 +1,74 @@
+package golog
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestSetLevelWhileLogging(t *testing.T) {
+	logger := New(INFO, io.Discard)
+	derived := logger.WithFields(map[string]interface{}{"k": "v"})
+
+	// the level keeps changing until every goroutine is done logging
+	stop := make(chan struct{})
+	flipped := make(chan struct{})
+	go func() {
+		defer close(flipped)
+		levels := []Level{DEBUG, INFO, WARN, ERROR}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				logger.SetLevel(levels[i%len(levels)])
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				derived.Debugln("debug")
+				derived.Infoln("info")
+				derived.IsLevelEnabled(DEBUG)
+				derived.Level()
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-flipped
+
+	logger.SetLevel(WARN)
+	if got := derived.Level(); got != WARN {
+		t.Errorf("derived logger level = %v, want %v", got, WARN)
+	}
+}
+
+func TestAtLevel(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	debug := logger.AtLevel(DEBUG)
+
+	debug.Debugln("from the copy")
+	if got := decodeEntry(t, buf)["message"]; got != "from the copy" {
+		t.Errorf("message = %v, want the debug entry of the copy", got)
+	}
+	buf.Reset()
+
+	logger.Debugln("from the parent")
+	if buf.Len() != 0 {
+		t.Errorf("parent logged %q at debug, want nothing", buf.String())
+	}
+	if got := logger.Level(); got != INFO {
+		t.Errorf("parent level = %v, want %v", got, INFO)
+	}
+
+	logger.SetLevel(ERROR)
+	if got := debug.Level(); got != DEBUG {
+		t.Errorf("copy level = %v after changing the parent, want %v", got, DEBUG)
+	}
+}
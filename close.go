@@ -0,0 +1,60 @@
+package golog
+
+import (
+	"io"
+	"reflect"
+)
+
+// Close flushes and closes what the logger buffers or writes to: the asynchronous writer of
+// NewAsync, the outputs golog opened such as the NewRotating file or the NewSyslog
+// connection, the NewOTLP exporter, and every hook implementing io.Closer. It returns the
+// first error encountered. It is a no-op for plain loggers writing to os.Stdout.
+//
+// Services should call it once at shutdown, typically with defer logger.Close() in main.
+// The logger and the loggers sharing its underlying logger must not be used afterwards.
+func (l Logger) Close() error {
+	var firstErr error
+	keep := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if l.core != nil {
+		l.core.mu.Lock()
+		closers := l.core.closers
+		l.core.closers = nil
+		l.core.mu.Unlock()
+
+		for _, closer := range closers {
+			keep(closer())
+		}
+	}
+
+	seen := map[interface{}]bool{}
+	for _, hooks := range l.logger.Logger.Hooks {
+		for _, hook := range hooks {
+			closer, ok := hook.(io.Closer)
+			if !ok {
+				continue
+			}
+			if reflect.TypeOf(hook).Comparable() {
+				if seen[hook] {
+					continue
+				}
+				seen[hook] = true
+			}
+			keep(closer.Close())
+		}
+	}
+
+	return firstErr
+}
+
+// addCloser registers a function to call when the logger is closed.
+func (c *core) addCloser(closer func() error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closers = append(c.closers, closer)
+}
@@ -0,0 +1,72 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slowWriter is a writer taking time to write, for entries to pile up in the async buffer.
+type slowWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) lines() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return bytes.Count(w.buf.Bytes(), []byte("\n"))
+}
+
+func TestCloseDrainsAsync(t *testing.T) {
+	w := &slowWriter{}
+	logger, _ := NewAsync(INFO, w, 100)
+
+	for i := 0; i < 50; i++ {
+		logger.Infoln("queued")
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := w.lines(); got != 50 {
+		t.Errorf("got %d entries written, want 50", got)
+	}
+}
+
+// closingHook records how many times it was closed.
+type closingHook struct {
+	closed int
+	err    error
+}
+
+func (h *closingHook) Levels() []logrus.Level   { return logrus.AllLevels }
+func (h *closingHook) Fire(*logrus.Entry) error { return nil }
+func (h *closingHook) Close() error             { h.closed++; return h.err }
+
+func TestCloseHooks(t *testing.T) {
+	logger := New(INFO, nil)
+	failing := &closingHook{err: errors.New("flush failed")}
+	other := &closingHook{}
+	logger.AddHook(failing)
+	logger.AddHook(other)
+
+	if err := logger.Close(); err != failing.err {
+		t.Errorf("Close() error = %v, want %v", err, failing.err)
+	}
+	if failing.closed != 1 || other.closed != 1 {
+		t.Errorf("hooks closed %d and %d times, want once each", failing.closed, other.closed)
+	}
+}
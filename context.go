@@ -0,0 +1,47 @@
+package golog
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	contextFieldsMu sync.RWMutex
+	contextFields   = map[interface{}]string{
+		ContextKeyRequestID: string(ContextKeyRequestID),
+	}
+)
+
+// RegisterContextKey registers a context key whose value WithContext adds to the logger
+// under the given field name. The request ID is registered by default.
+func RegisterContextKey(key interface{}, field string) {
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+
+	contextFields[key] = field
+}
+
+// WithContext returns a new logger with the values of the registered context keys found in
+// ctx added as fields. Keys missing from ctx are skipped. ctx is also attached to the entries
+// so that hooks can read it from logrus.Entry.Context.
+func (l Logger) WithContext(ctx context.Context) Logger {
+	if l.core != nil && l.core.nop {
+		return l
+	}
+	l.logger = l.logger.WithContext(ctx)
+
+	contextFieldsMu.RLock()
+	fields := make(map[string]interface{}, len(contextFields))
+	for key, field := range contextFields {
+		if val := ctx.Value(key); val != nil {
+			fields[field] = val
+		}
+	}
+	contextFieldsMu.RUnlock()
+
+	if len(fields) == 0 {
+		return l
+	}
+
+	return l.WithFields(fields)
+}
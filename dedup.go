@@ -0,0 +1,89 @@
+package golog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxDedupKeys bounds the number of distinct entries tracked at once. Once reached, new
+// entries are emitted without being deduplicated until tracked windows close.
+const maxDedupKeys = 10000
+
+// WithDedup collapses identical entries, same level, message and fields, logged within
+// window of the first one. The first entry is emitted right away, and when the window closes
+// a copy of it carrying an OccurrencesKey field is emitted if it was logged more than once.
+func WithDedup(window time.Duration) Option {
+	return func(o *options) {
+		o.dedupWindow = window
+	}
+}
+
+type deduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*int
+}
+
+func newDeduper(window time.Duration) *deduper {
+	if window <= 0 {
+		return nil
+	}
+
+	return &deduper{
+		window: window,
+		seen:   make(map[string]*int),
+	}
+}
+
+// admit reports whether the entry identified by key should be emitted. When it starts a new
+// window, summary is called with the number of occurrences once the window closes.
+func (d *deduper) admit(key string, summary func(occurrences int)) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if count, ok := d.seen[key]; ok {
+		*count++
+		return false
+	}
+	if len(d.seen) >= maxDedupKeys {
+		return true
+	}
+
+	count := 1
+	d.seen[key] = &count
+	time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		occurrences := count
+		delete(d.seen, key)
+		d.mu.Unlock()
+
+		if occurrences > 1 {
+			summary(occurrences)
+		}
+	})
+
+	return true
+}
+
+// dedupKey identifies an entry by its level, message and fields.
+func dedupKey(level logrus.Level, msg string, data logrus.Fields) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d|%s", level, msg)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "|%s=%v", k, data[k])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger Logger
+)
+
+// Default returns the package-level logger used by Printf and Println. It is created with
+// NewDefault on first use, unless set by SetDefault.
+func Default() Logger {
+	defaultMu.RLock()
+	logger := defaultLogger
+	defaultMu.RUnlock()
+	if !logger.IsZero() {
+		return logger
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultLogger.IsZero() {
+		defaultLogger = NewDefault()
+	}
+	return defaultLogger
+}
+
+// SetDefault replaces the package-level logger.
+func SetDefault(logger Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultLogger = logger
+}
+
+// Printf logs the formatted message at info level with the package-level logger, as a
+// replacement for log.Printf.
+func Printf(format string, args ...interface{}) {
+	Default().Logf(INFO, format, args...)
+}
+
+// Println logs the operands formatted as by fmt.Sprintln at info level with the package-level
+// logger, as a replacement for log.Println.
+func Println(args ...interface{}) {
+	Default().Infoln(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
@@ -0,0 +1,49 @@
+package golog
+
+import "testing"
+
+// setTestDefault replaces the package-level logger for the duration of the test.
+func setTestDefault(t *testing.T, logger Logger) {
+	t.Helper()
+
+	defaultMu.RLock()
+	previous := defaultLogger
+	defaultMu.RUnlock()
+	t.Cleanup(func() { SetDefault(previous) })
+
+	SetDefault(logger)
+}
+
+func TestDefault(t *testing.T) {
+	setTestDefault(t, Logger{})
+
+	if Default().IsZero() {
+		t.Fatalf("Default() returned a zero logger, want one created on first use")
+	}
+
+	logger, buf := newTestLogger(t, INFO)
+	SetDefault(logger)
+	Default().Infoln("through Default")
+	if got := decodeEntry(t, buf)["message"]; got != "through Default" {
+		t.Errorf("message = %v, want the entry of the logger set by SetDefault", got)
+	}
+}
+
+func TestPrintfPrintln(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG)
+	setTestDefault(t, logger)
+
+	Printf("user %d logged in", 42)
+	Println("user", 42, "logged out")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0]["message"] != "user 42 logged in" || entries[0]["severity"] != "info" {
+		t.Errorf("Printf entry = %v, want the formatted message at info", entries[0])
+	}
+	if entries[1]["message"] != "user 42 logged out" || entries[1]["severity"] != "info" {
+		t.Errorf("Println entry = %v, want the operands without a trailing newline at info", entries[1])
+	}
+}
@@ -0,0 +1,52 @@
+package golog
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a single logged event, independent of the formatter and of the library golog is
+// built on. It is what observers and sinks receive, and the stable representation of an
+// entry across golog versions, unlike the logrus types exposed by Logger.Entry and Hook.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	// Fields holds the fields of the entry, including the ones golog adds such as
+	// StacktraceKey. It is a copy the receiver is free to modify.
+	Fields map[string]interface{}
+}
+
+// EntryOf returns the Entry of a logrus entry, for hooks registered with AddHook to work on
+// golog's representation.
+func EntryOf(e *logrus.Entry) Entry {
+	return newEntry(e)
+}
+
+func newEntry(e *logrus.Entry) Entry {
+	fields := make(map[string]interface{}, len(e.Data))
+	for k, v := range e.Data {
+		fields[k] = v
+	}
+
+	return Entry{
+		Time:    e.Time,
+		Level:   fromLogrusLevel(e.Level),
+		Message: e.Message,
+		Fields:  fields,
+	}
+}
+
+func fromLogrusLevel(l logrus.Level) Level {
+	switch l {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return DEBUG
+	case logrus.InfoLevel:
+		return INFO
+	case logrus.WarnLevel:
+		return WARN
+	default:
+		return ERROR
+	}
+}
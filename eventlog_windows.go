@@ -0,0 +1,54 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogEventID is the event ID of the entries written to the Windows Event Log.
+const eventLogEventID = 1
+
+// EventLogSink is a Sink writing entries to the Windows Event Log. The fields are appended to
+// the message as a JSON object.
+type EventLogSink struct {
+	log *eventlog.Log
+}
+
+// NewEventLogSink creates a new sink writing to the Windows Event Log under the given event
+// source, which must have been registered, for instance with eventlog.InstallAsEventCreate.
+func NewEventLogSink(source string) (*EventLogSink, error) {
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("golog: event log unavailable: %w", err)
+	}
+
+	return &EventLogSink{log: log}, nil
+}
+
+// Write implements Sink.
+func (s *EventLogSink) Write(entry Entry) error {
+	msg := entry.Message
+	if len(entry.Fields) > 0 {
+		b, err := json.Marshal(entry.Fields)
+		if err != nil {
+			b = []byte(fmt.Sprintf("%v", entry.Fields))
+		}
+		msg = fmt.Sprintf("%s %s", msg, b)
+	}
+
+	switch entry.Level {
+	case DEBUG, INFO:
+		return s.log.Info(eventLogEventID, msg)
+	case WARN:
+		return s.log.Warning(eventLogEventID, msg)
+	default:
+		return s.log.Error(eventLogEventID, msg)
+	}
+}
+
+// Close closes the event log.
+func (s *EventLogSink) Close() error {
+	return s.log.Close()
+}
@@ -0,0 +1,76 @@
+package golog
+
+import "time"
+
+// Field is a typed key value pair, built by String, Int, Bool and the other helpers and
+// added with WithTyped, so that the types of the fields are checked at compile time. It
+// allocates as much as WithFields.
+type Field struct {
+	Key   string
+	value interface{}
+}
+
+// String returns a string field.
+func String(key, val string) Field {
+	return Field{Key: key, value: val}
+}
+
+// Int returns an int field.
+func Int(key string, val int) Field {
+	return Field{Key: key, value: int64(val)}
+}
+
+// Int64 returns an int64 field.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, value: val}
+}
+
+// Float64 returns a float64 field.
+func Float64(key string, val float64) Field {
+	return Field{Key: key, value: val}
+}
+
+// Bool returns a bool field.
+func Bool(key string, val bool) Field {
+	return Field{Key: key, value: val}
+}
+
+// Duration returns a time.Duration field.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, value: val}
+}
+
+// Time returns a time.Time field.
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, value: val}
+}
+
+// Err returns a field holding err under ErrorKey, see WithError.
+func Err(err error) Field {
+	return Any(ErrorKey, err)
+}
+
+// Any returns a field of any type.
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, value: val}
+}
+
+// Value returns the value of the field.
+func (f Field) Value() interface{} {
+	return f.value
+}
+
+// WithTyped returns a new logger with the typed fields added, as WithFields does. A field
+// repeated later takes precedence.
+func (l Logger) WithTyped(fields ...Field) Logger {
+	if len(fields) == 0 || l.core != nil && l.core.nop {
+		return l
+	}
+
+	m := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		m[f.Key] = f.value
+	}
+
+	return l.WithFields(m)
+}
@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWithTyped(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	logger.WithTyped(
+		String("s", "v"),
+		Int("i", 1),
+		Int64("i64", 1<<40),
+		Float64("f", 1.5),
+		Bool("b", true),
+		Duration("d", time.Second),
+		Time("t", at),
+		Any("a", []string{"x"}),
+		Err(errors.New("failed")),
+	).Infoln("typed")
+
+	entry := decodeEntry(t, buf)
+	want := map[string]interface{}{
+		"s":      "v",
+		"i":      float64(1),
+		"i64":    float64(1 << 40),
+		"f":      1.5,
+		"b":      true,
+		"d":      float64(time.Second),
+		"t":      at.Format(time.RFC3339),
+		ErrorKey: "failed",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v (%T), want %v (%T)", k, entry[k], entry[k], v, v)
+		}
+	}
+	if a, ok := entry["a"].([]interface{}); !ok || len(a) != 1 || a[0] != "x" {
+		t.Errorf("a = %v, want [x]", entry["a"])
+	}
+	if entry[StacktraceKey] != "failed" {
+		t.Errorf("%s = %v, want failed", StacktraceKey, entry[StacktraceKey])
+	}
+}
+
+func BenchmarkWithTyped(b *testing.B) {
+	logger := New(INFO, io.Discard)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithTyped(
+			String("user", "u1"),
+			Int("attempt", 2),
+			Bool("cached", true),
+			Duration("elapsed", time.Millisecond),
+		).Infoln("typed")
+	}
+}
+
+// BenchmarkWithTypedFieldsMap is the WithFields equivalent of BenchmarkWithTyped.
+func BenchmarkWithTypedFieldsMap(b *testing.B) {
+	logger := New(INFO, io.Discard)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(map[string]interface{}{
+			"user":    "u1",
+			"attempt": 2,
+			"cached":  true,
+			"elapsed": time.Millisecond,
+		}).Infoln("typed")
+	}
+}
@@ -0,0 +1,174 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// Format of the log entries
+type Format int
+
+// Formats supported
+const (
+	// FormatJSON writes an entry as a JSON object per line, it is the default.
+	FormatJSON Format = iota
+	// FormatText writes human readable key=value lines, meant for local development.
+	FormatText
+	// FormatGCP writes JSON objects following the Google Cloud Logging structured logging
+	// format, with uppercase severities and, with WithCaller, the source location. The time,
+	// level and message fields are named as Cloud Logging expects, NewWithOptions rejects
+	// WithFieldNames.
+	FormatGCP
+)
+
+// WithFormat selects the format of the log entries.
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithColor enables or disables colored levels with FormatText. By default colors are
+// enabled only when the output is a terminal, so that piped logs stay clean. Like the other
+// text options, NewWithOptions rejects it with another format.
+func WithColor(enabled bool) Option {
+	return func(o *options) {
+		o.color = &enabled
+	}
+}
+
+// WithDisableTimestamp drops the timestamp from FormatText entries.
+func WithDisableTimestamp(disabled bool) Option {
+	return func(o *options) {
+		o.text.DisableTimestamp = disabled
+	}
+}
+
+// WithFullTimestamp writes the full timestamp of FormatText entries instead of the seconds
+// elapsed since the start when the output is a terminal.
+func WithFullTimestamp(enabled bool) Option {
+	return func(o *options) {
+		o.text.FullTimestamp = enabled
+	}
+}
+
+// WithPadLevelText pads the levels of colored FormatText entries to the same width, so that
+// messages are aligned.
+func WithPadLevelText(enabled bool) Option {
+	return func(o *options) {
+		o.text.PadLevelText = enabled
+	}
+}
+
+// WithOmitEmptyMessage drops the message field from FormatJSON entries logged with an empty
+// message, such as Infoln(""), instead of writing it with an empty value. FormatText
+// always omits it.
+func WithOmitEmptyMessage() Option {
+	return func(o *options) {
+		o.omitEmptyMessage = true
+	}
+}
+
+// WithPrettyJSON indents FormatJSON and FormatGCP entries over multiple lines, for local
+// debugging. Log collectors expect one entry per line, keep it disabled in production.
+// NewWithOptions rejects it with FormatText.
+func WithPrettyJSON(enabled bool) Option {
+	return func(o *options) {
+		o.prettyJSON = enabled
+	}
+}
+
+func newFormatter(options options, o io.Writer) logrus.Formatter {
+	fieldMap := logrus.FieldMap{
+		logrus.FieldKeyTime:  options.timeKey,
+		logrus.FieldKeyLevel: options.levelKey,
+		logrus.FieldKeyMsg:   options.messageKey,
+	}
+
+	switch options.format {
+	case FormatText:
+		color := isTerminal(o)
+		if options.color != nil {
+			color = *options.color
+		}
+		return &logrus.TextFormatter{
+			FieldMap:         fieldMap,
+			TimestampFormat:  time.RFC3339Nano,
+			ForceColors:      color,
+			DisableColors:    !color,
+			DisableTimestamp: options.text.DisableTimestamp,
+			FullTimestamp:    options.text.FullTimestamp,
+			PadLevelText:     options.text.PadLevelText,
+		}
+	case FormatGCP:
+		return &gcpFormatter{
+			prettyPrint:      options.prettyJSON,
+			omitEmptyMessage: options.omitEmptyMessage,
+		}
+	default:
+		formatter := &logrus.JSONFormatter{
+			FieldMap:        fieldMap,
+			TimestampFormat: time.RFC3339Nano,
+			PrettyPrint:     options.prettyJSON,
+		}
+		if !options.omitEmptyMessage {
+			return formatter
+		}
+		return &jsonFormatter{
+			JSONFormatter:    formatter,
+			messageKey:       options.messageKey,
+			omitEmptyMessage: options.omitEmptyMessage,
+		}
+	}
+}
+
+// jsonFormatter rewrites the top-level fields of the entries formatted by logrus's
+// JSONFormatter, for the options it doesn't support.
+type jsonFormatter struct {
+	*logrus.JSONFormatter
+	messageKey       string
+	omitEmptyMessage bool
+}
+
+func (f *jsonFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.JSONFormatter.Format(entry)
+	if err != nil || !f.omitEmptyMessage || entry.Message != "" {
+		return b, err
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	delete(data, f.messageKey)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if f.PrettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// textOptions are the options specific to FormatText.
+type textOptions struct {
+	DisableTimestamp bool
+	FullTimestamp    bool
+	PadLevelText     bool
+}
+
+func isTerminal(o io.Writer) bool {
+	f, ok := o.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,157 @@
+package golog
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeJSON decodes a single JSON entry, pretty printed or not.
+func decodeJSON(t *testing.T, b []byte) map[string]interface{} {
+	t.Helper()
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatalf("invalid JSON entry %q: %v", b, err)
+	}
+	return entry
+}
+
+func TestWithOmitEmptyMessage(t *testing.T) {
+	for _, pretty := range []bool{false, true} {
+		logger, buf := newTestLogger(t, INFO, WithOmitEmptyMessage(), WithPrettyJSON(pretty))
+		logger.WithFields(map[string]interface{}{"k": "v"}).Infoln("")
+
+		entry := decodeJSON(t, buf.Bytes())
+		if _, ok := entry["message"]; ok {
+			t.Errorf("pretty %v: message present in %v", pretty, entry)
+		}
+		if entry["k"] != "v" || entry["severity"] != "info" {
+			t.Errorf("pretty %v: fields missing in %v", pretty, entry)
+		}
+	}
+}
+
+func TestWithPrettyJSON(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO, WithPrettyJSON(true), WithFieldNames("ts", "", "msg"))
+	logger.WithFields(map[string]interface{}{"k": "v"}).Infoln("pretty")
+
+	out := buf.String()
+	if strings.Count(out, "\n") < 5 {
+		t.Fatalf("output isn't indented over multiple lines: %q", out)
+	}
+	if !strings.Contains(out, "\n  \"msg\": \"pretty\"") {
+		t.Errorf("output isn't indented by two spaces: %q", out)
+	}
+
+	entry := decodeJSON(t, buf.Bytes())
+	if _, err := time.Parse(time.RFC3339Nano, entry["ts"].(string)); err != nil {
+		t.Errorf("ts = %v isn't RFC 3339: %v", entry["ts"], err)
+	}
+	if entry["severity"] != "info" || entry["k"] != "v" {
+		t.Errorf("fields missing in %v", entry)
+	}
+}
+
+func TestWithPrettyJSONDisabled(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO, WithPrettyJSON(false))
+	logger.WithFields(map[string]interface{}{"k": "v"}).Infoln("compact")
+
+	if out := buf.String(); strings.Count(out, "\n") != 1 {
+		t.Errorf("output isn't a single line: %q", out)
+	}
+}
+
+func TestTextOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		want     string
+		wantNone string
+	}{
+		{name: "timestamp", opts: []Option{WithColor(false)}, want: `^timestamp=`},
+		{name: "disable timestamp", opts: []Option{WithColor(false), WithDisableTimestamp(true)}, want: `^severity=info message=hello\n$`, wantNone: `timestamp=`},
+		{name: "elapsed seconds", opts: []Option{WithColor(true)}, want: `\[\d{4}\] hello`},
+		{name: "full timestamp", opts: []Option{WithColor(true), WithFullTimestamp(true)}, want: `\[\d{4}-\d{2}-\d{2}T[^\]]+\] hello`, wantNone: `\[\d{4}\]`},
+		{name: "pad level text", opts: []Option{WithColor(true), WithPadLevelText(true)}, want: `INFO   \x1b\[0m`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger(t, INFO, append([]Option{WithFormat(FormatText)}, tt.opts...)...)
+			logger.Infoln("hello")
+
+			if out := buf.String(); !regexp.MustCompile(tt.want).MatchString(out) {
+				t.Errorf("output = %q, want it to match %s", out, tt.want)
+			}
+			if out := buf.String(); tt.wantNone != "" && regexp.MustCompile(tt.wantNone).MatchString(out) {
+				t.Errorf("output = %q, want it not to match %s", out, tt.wantNone)
+			}
+		})
+	}
+}
+
+// Text options aren't silently ignored with the JSON formats, NewWithOptions rejects them.
+func TestTextOptionsWithJSONFormat(t *testing.T) {
+	textOptions := map[string]Option{
+		"WithColor":            WithColor(true),
+		"WithDisableTimestamp": WithDisableTimestamp(true),
+		"WithFullTimestamp":    WithFullTimestamp(true),
+		"WithPadLevelText":     WithPadLevelText(true),
+	}
+
+	for name, opt := range textOptions {
+		for _, format := range []Format{FormatJSON, FormatGCP} {
+			if _, err := NewWithOptions(INFO, io.Discard, WithFormat(format), opt); err == nil || !strings.Contains(err.Error(), "require FormatText") {
+				t.Errorf("%s with format %d: error = %v, want it rejected", name, format, err)
+			}
+		}
+	}
+}
+
+func TestNewWithOptionsConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantError string
+	}{
+		{name: "FormatGCP with WithFieldNames", opts: []Option{WithFormat(FormatGCP), WithFieldNames("ts", "", "")}, wantError: "WithFieldNames can't be used with FormatGCP"},
+		{name: "FormatText with WithPrettyJSON", opts: []Option{WithFormat(FormatText), WithPrettyJSON(true)}, wantError: "WithPrettyJSON can't be used with FormatText"},
+		{name: "FormatJSON with WithColor", opts: []Option{WithColor(false)}, wantError: "require FormatText"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWithOptions(INFO, io.Discard, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Fatalf("NewWithOptions() error = %v, want it to contain %q", err, tt.wantError)
+			}
+
+			defer func() {
+				if r, _ := recover().(error); r == nil || r.Error() != err.Error() {
+					t.Errorf("MustNewWithOptions() panicked with %v, want %v", r, err)
+				}
+			}()
+			MustNewWithOptions(INFO, io.Discard, tt.opts...)
+		})
+	}
+}
+
+func TestNewWithOptionsCompatible(t *testing.T) {
+	valid := [][]Option{
+		{WithFormat(FormatText), WithColor(false), WithDisableTimestamp(true)},
+		{WithFormat(FormatGCP), WithPrettyJSON(true)},
+		{WithFieldNames("ts", "level", "msg"), WithPrettyJSON(true)},
+		{WithFormat(FormatText), WithFieldNames("ts", "level", "msg")},
+	}
+
+	for _, opts := range valid {
+		if _, err := NewWithOptions(INFO, io.Discard, opts...); err != nil {
+			t.Errorf("NewWithOptions() error = %v, want none", err)
+		}
+		MustNewWithOptions(INFO, io.Discard, opts...)
+	}
+}
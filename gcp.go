@@ -0,0 +1,93 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Fields of the Cloud Logging structured format, see
+// https://cloud.google.com/logging/docs/structured-logging
+const (
+	gcpTimeKey           = "time"
+	gcpSeverityKey       = "severity"
+	gcpMessageKey        = "message"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+)
+
+// gcpFormatter formats entries for FormatGCP.
+type gcpFormatter struct {
+	prettyPrint      bool
+	omitEmptyMessage bool
+}
+
+type gcpSourceLocation struct {
+	File     string `json:"file"`
+	Line     string `json:"line"`
+	Function string `json:"function"`
+}
+
+func (f *gcpFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+4)
+	for k, v := range entry.Data {
+		switch val := v.(type) {
+		case error:
+			// errors implementing no marshaling would be logged as {}
+			v = val.Error()
+		case Caller:
+			if k == CallerKey {
+				data[gcpSourceLocationKey] = gcpSourceLocation{
+					File:     val.File,
+					Line:     strconv.Itoa(val.Line),
+					Function: val.Function,
+				}
+				continue
+			}
+		}
+
+		switch k {
+		case gcpTimeKey, gcpSeverityKey, gcpMessageKey:
+			// keep the fields clashing with the special ones, as logrus does
+			k = "fields." + k
+		}
+		data[k] = v
+	}
+
+	data[gcpTimeKey] = entry.Time.Format(time.RFC3339Nano)
+	data[gcpSeverityKey] = gcpSeverity(fromLogrusLevel(entry.Level))
+	if entry.Message != "" || !f.omitEmptyMessage {
+		data[gcpMessageKey] = entry.Message
+	}
+
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+	encoder := json.NewEncoder(b)
+	if f.prettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON, %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// gcpSeverity maps a level to its Cloud Logging severity.
+func gcpSeverity(l Level) string {
+	switch l {
+	case DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case WARN:
+		return "WARNING"
+	default:
+		return "ERROR"
+	}
+}
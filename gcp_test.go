@@ -0,0 +1,69 @@
+package golog
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatGCP(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG, WithFormat(FormatGCP), WithCaller())
+
+	logger.WithFields(map[string]interface{}{"k": "v", "severity": "custom"}).WithError(errors.New("failed")).Warnln("hello")
+
+	entry := decodeEntry(t, buf)
+	if entry["message"] != "hello" || entry["severity"] != "WARNING" {
+		t.Errorf("message, severity = %v, %v, want hello, WARNING", entry["message"], entry["severity"])
+	}
+	if _, err := time.Parse(time.RFC3339Nano, entry["time"].(string)); err != nil {
+		t.Errorf("time = %v isn't RFC 3339: %v", entry["time"], err)
+	}
+	for _, key := range []string{"timestamp", "msg", CallerKey} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("%s present in %v, want the Cloud Logging fields only", key, entry)
+		}
+	}
+	if entry["fields.severity"] != "custom" || entry["k"] != "v" || entry[ErrorKey] != "failed" {
+		t.Errorf("fields missing in %v", entry)
+	}
+
+	// the frames of this package, tests included, are skipped: the caller is the test runner
+	location, _ := entry[gcpSourceLocationKey].(map[string]interface{})
+	if file, _ := location["file"].(string); !strings.HasSuffix(file, "testing.go") {
+		t.Errorf("sourceLocation.file = %v, want testing.go", location["file"])
+	}
+	line, _ := location["line"].(string)
+	if n, err := strconv.Atoi(line); err != nil || n == 0 {
+		t.Errorf("sourceLocation.line = %v, want the line as a string", location["line"])
+	}
+	if location["function"] != "testing.tRunner" {
+		t.Errorf("sourceLocation.function = %v, want testing.tRunner", location["function"])
+	}
+}
+
+func TestFormatGCPSeverities(t *testing.T) {
+	want := map[Level]string{DEBUG: "DEBUG", INFO: "INFO", WARN: "WARNING", ERROR: "ERROR"}
+
+	for level, severity := range want {
+		logger, buf := newTestLogger(t, DEBUG, WithFormat(FormatGCP))
+		logger.Logln(level, "hello")
+
+		if got := decodeEntry(t, buf)["severity"]; got != severity {
+			t.Errorf("level %v: severity = %v, want %s", level, got, severity)
+		}
+	}
+}
+
+func TestFormatGCPStdLoggerCaller(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG, WithFormat(FormatGCP), WithCaller())
+
+	logger.StdLogger(INFO).Printf("hello")
+
+	// the frames of the log package are skipped as well
+	location, _ := decodeEntry(t, buf)[gcpSourceLocationKey].(map[string]interface{})
+	if location["function"] != "testing.tRunner" {
+		t.Errorf("sourceLocation.function = %v, want testing.tRunner", location["function"])
+	}
+}
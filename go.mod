@@ -3,7 +3,20 @@ module github.com/cvemprala/golog
 go 1.18
 
 require (
-	github.com/google/uuid v1.3.0 // indirect
-	github.com/sirupsen/logrus v1.9.0 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/sirupsen/logrus v1.9.0
+	go.opentelemetry.io/otel/trace v1.14.0
+	golang.org/x/sys v0.8.0
+	golang.org/x/term v0.8.0
+	google.golang.org/grpc v1.57.2
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	go.opentelemetry.io/otel v1.14.0 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19 // indirect
+	google.golang.org/protobuf v1.30.0 // indirect
 )
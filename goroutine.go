@@ -0,0 +1,33 @@
+package golog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// GoroutineIDKey holds the ID of the goroutine that logged the entry, see WithGoroutineID.
+const GoroutineIDKey = "goroutineID"
+
+// WithGoroutineID adds the ID of the goroutine logging each entry under GoroutineIDKey, for
+// debugging concurrency issues. Go doesn't expose goroutine IDs, it is parsed from the
+// header of runtime.Stack on every entry and can't be cached, so keep it for debugging.
+func WithGoroutineID() Option {
+	return func(o *options) {
+		o.goroutineID = true
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the "goroutine 1 [running]:"
+// header of its stack.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
@@ -0,0 +1,51 @@
+package golog
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestWithGoroutineID(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO, WithGoroutineID())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Infoln("first")
+			logger.Infoln("second")
+		}()
+	}
+	wg.Wait()
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+	counts := make(map[float64]int)
+	for _, e := range entries {
+		id, _ := e[GoroutineIDKey].(float64)
+		if id == 0 {
+			t.Fatalf("%s = %v, want a goroutine ID", GoroutineIDKey, e[GoroutineIDKey])
+		}
+		counts[id]++
+	}
+	if len(counts) != 2 {
+		t.Errorf("goroutine IDs = %v, want two distinct ones", counts)
+	}
+	for id, n := range counts {
+		if n != 2 {
+			t.Errorf("goroutine %v logged %d entries, want 2", id, n)
+		}
+	}
+}
+
+func TestWithoutGoroutineID(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	logger.Infoln("hello")
+
+	if _, ok := decodeEntry(t, buf)[GoroutineIDKey]; ok {
+		t.Errorf("%s present without WithGoroutineID", GoroutineIDKey)
+	}
+}
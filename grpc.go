@@ -0,0 +1,63 @@
+package golog
+
+import (
+	"context"
+	"os"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadataKey is the gRPC metadata key carrying the request ID, the counterpart of
+// the Request-ID HTTP header.
+const requestIDMetadataKey = "request-id"
+
+// UnaryServerInterceptor creates a new gRPC interceptor for logging, the gRPC counterpart of
+// NewMiddleware. The request ID is taken from the incoming metadata when present, otherwise a
+// new one is generated. Each call is logged as a "grpc_request" entry, at error level with
+// the error when it fails.
+func UnaryServerInterceptor(logger Logger) grpc.UnaryServerInterceptor {
+	if logger.IsZero() {
+		logger = New(INFO, os.Stdout)
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := logger.now()
+
+		// attach request ID to the context
+		requestID := incomingRequestID(ctx)
+		ctx = ContextWithRequestID(ctx, requestID)
+
+		// attach the request ID to the logger
+		loggerWithRequestID := logger.WithFields(map[string]interface{}{string(ContextKeyRequestID): requestID})
+		ctx = WithLogger(ctx, loggerWithRequestID)
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		entry := loggerWithRequestID.WithFields(map[string]interface{}{
+			"method":   info.FullMethod,
+			"duration": logger.since(start),
+			"code":     code.String(),
+		})
+		if err != nil {
+			entry.WithFields(map[string]interface{}{ErrorKey: err}).Errorln("grpc_request")
+		} else {
+			entry.Debugln("grpc_request")
+		}
+
+		return resp, err
+	}
+}
+
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+
+	return uuid.New().String()
+}
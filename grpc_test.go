@@ -0,0 +1,87 @@
+package golog
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"}
+	tests := []struct {
+		name      string
+		err       error
+		wantLevel Level
+		wantCode  string
+	}{
+		{name: "success", wantLevel: DEBUG, wantCode: codes.OK.String()},
+		{name: "failure", err: status.Error(codes.NotFound, "no such user"), wantLevel: ERROR, wantCode: codes.NotFound.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, observer := NewObserver(DEBUG)
+			interceptor := UnaryServerInterceptor(logger)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDMetadataKey, "req-1"))
+			var handlerRequestID string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerRequestID, _ = RequestIDFromContext(ctx)
+				GetLogger(ctx).Infoln("handling")
+				return "response", tt.err
+			}
+
+			resp, err := interceptor(ctx, "request", info, handler)
+			if resp != "response" || err != tt.err {
+				t.Errorf("interceptor() = %v, %v, want the handler's response and error", resp, err)
+			}
+			if handlerRequestID != "req-1" {
+				t.Errorf("handler request ID = %q, want the one of the metadata", handlerRequestID)
+			}
+
+			handling := entriesWithMessage(observer, "handling")
+			if len(handling) != 1 || handling[0].Fields[string(ContextKeyRequestID)] != "req-1" {
+				t.Errorf("handler entries = %v, want one with the request ID", handling)
+			}
+			entries := entriesWithMessage(observer, "grpc_request")
+			if len(entries) != 1 {
+				t.Fatalf("got %d grpc_request entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.wantLevel || entry.Fields["code"] != tt.wantCode {
+				t.Errorf("level, code = %v, %v, want %v, %v", entry.Level, entry.Fields["code"], tt.wantLevel, tt.wantCode)
+			}
+			if entry.Fields["method"] != info.FullMethod || entry.Fields[string(ContextKeyRequestID)] != "req-1" {
+				t.Errorf("fields = %v, want the method and the request ID", entry.Fields)
+			}
+			if entry.Fields[ErrorKey] != tt.err && tt.err != nil {
+				t.Errorf("error = %v, want %v", entry.Fields[ErrorKey], tt.err)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorNewRequestID(t *testing.T) {
+	logger, observer := NewObserver(DEBUG)
+
+	var handlerRequestID string
+	_, err := UnaryServerInterceptor(logger)(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerRequestID, _ = RequestIDFromContext(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+
+	if handlerRequestID == "" {
+		t.Fatalf("handler got no request ID, want a generated one")
+	}
+	entries := entriesWithMessage(observer, "grpc_request")
+	if len(entries) != 1 || entries[0].Fields[string(ContextKeyRequestID)] != handlerRequestID {
+		t.Errorf("entries = %v, want one with the generated request ID %s", entries, handlerRequestID)
+	}
+}
@@ -0,0 +1,75 @@
+package golog
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HandlerFunc is an HTTP handler returning an error, adapted to an http.Handler by WrapError.
+type HandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// HTTPError is an error carrying the status code WrapError responds with.
+type HTTPError struct {
+	Status int
+	Err    error
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the status code of the error.
+func (e *HTTPError) StatusCode() int {
+	return e.Status
+}
+
+// WrapError adapts h to an http.Handler. An error returned by h is logged at error level
+// with its stacktrace and, unless h already responded, turned into a response with the status
+// of the first error in its chain having a StatusCode() int method, such as HTTPError, or 500.
+// Behind NewMiddleware, the error is logged with the request logger and added to the
+// response log entry as well.
+func WrapError(logger Logger, h HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder, ok := w.(*ResponseWriterRecorder)
+		if !ok {
+			recorder = NewResponseWriterRecorder(w)
+		}
+
+		err := h(recorder, r)
+		if err == nil {
+			return
+		}
+
+		// logger is shared by all the requests, the request logger is only used for this one
+		reqLogger := logger
+		if requestLogger, ok := r.Context().Value(ContextKeyLogger).(Logger); ok && !requestLogger.IsZero() {
+			reqLogger = requestLogger
+		}
+		reqLogger.WithError(err).Errorln("handler error")
+		if state := requestStateFrom(r.Context()); state != nil {
+			state.err = err
+		}
+
+		if !recorder.isStatusSet {
+			status := errorStatus(err)
+			http.Error(recorder, http.StatusText(status), status)
+		}
+	})
+}
+
+// errorStatus returns the status code carried by the error chain, or 500.
+func errorStatus(err error) int {
+	var statusCoder interface{ StatusCode() int }
+	if errors.As(err, &statusCoder) {
+		return statusCoder.StatusCode()
+	}
+
+	return http.StatusInternalServerError
+}
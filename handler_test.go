@@ -0,0 +1,131 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWrapErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "no error", err: nil, wantStatus: http.StatusOK},
+		{name: "plain error", err: errors.New("failed"), wantStatus: http.StatusInternalServerError},
+		{name: "HTTPError", err: &HTTPError{Status: http.StatusNotFound}, wantStatus: http.StatusNotFound},
+		{
+			name:       "wrapped HTTPError",
+			err:        fmt.Errorf("loading user: %w", &HTTPError{Status: http.StatusForbidden, Err: errors.New("denied")}),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, observer := NewObserver(DEBUG)
+			handler := WrapError(logger, func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			})
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			entries := entriesWithMessage(observer, "handler error")
+			if tt.err == nil {
+				if len(entries) != 0 {
+					t.Errorf("got %d error entries, want none", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0].Level != ERROR {
+				t.Fatalf("got %v, want one error entry", entries)
+			}
+			if entries[0].Fields[ErrorKey] != tt.err {
+				t.Errorf("error = %v, want %v", entries[0].Fields[ErrorKey], tt.err)
+			}
+		})
+	}
+}
+
+func TestWrapErrorAfterResponse(t *testing.T) {
+	logger, observer := NewObserver(DEBUG)
+	handler := WrapError(logger, func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return &HTTPError{Status: http.StatusBadRequest}
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want the one the handler wrote", w.Code)
+	}
+	if len(entriesWithMessage(observer, "handler error")) != 1 {
+		t.Errorf("error not logged")
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	cause := errors.New("no such user")
+	err := &HTTPError{Status: http.StatusNotFound, Err: cause}
+
+	if err.Error() != "no such user" {
+		t.Errorf("Error() = %q, want the one of the cause", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(err, cause) = false, want true")
+	}
+	if err.StatusCode() != http.StatusNotFound {
+		t.Errorf("StatusCode() = %d, want %d", err.StatusCode(), http.StatusNotFound)
+	}
+	if got := (&HTTPError{Status: http.StatusTeapot}).Error(); got != http.StatusText(http.StatusTeapot) {
+		t.Errorf("Error() = %q, want the status text without a cause", got)
+	}
+}
+
+func TestWrapErrorConcurrentRequests(t *testing.T) {
+	const requests = 20
+
+	// the handlers return together, for the errors to be logged concurrently
+	var started sync.WaitGroup
+	started.Add(requests)
+	logger, observer := NewObserver(DEBUG)
+	handler := NewMiddleware(WrapError(logger, func(w http.ResponseWriter, r *http.Request) error {
+		started.Done()
+		started.Wait()
+		return errors.New(r.URL.Path)
+	}), logger)
+
+	requestIDs := make([]string, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+strconv.Itoa(i), nil))
+			requestIDs[i] = w.Header().Get("Request-ID")
+		}(i)
+	}
+	wg.Wait()
+
+	entries := entriesWithMessage(observer, "handler error")
+	if len(entries) != requests {
+		t.Fatalf("got %d error entries, want %d", len(entries), requests)
+	}
+	for _, e := range entries {
+		i, _ := strconv.Atoi(fmt.Sprint(e.Fields[ErrorKey])[1:])
+		if e.Fields[string(ContextKeyRequestID)] != requestIDs[i] {
+			t.Errorf("error of request %d logged with requestId %v, want %s", i, e.Fields[string(ContextKeyRequestID)], requestIDs[i])
+		}
+	}
+}
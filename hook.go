@@ -0,0 +1,48 @@
+package golog
+
+import "github.com/sirupsen/logrus"
+
+// Hook is fired for every entry logged at one of its levels, see logrus.Hook.
+type Hook = logrus.Hook
+
+// AddHook registers a hook on the underlying logger. The hook is shared by this logger and
+// every logger derived from the same New call. It is fired before the entry is written out,
+// so the changes it makes to the entry are part of the output.
+func (l Logger) AddHook(hook Hook) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	hooks := copyHooks(l.logger.Logger.Hooks)
+	for _, level := range hook.Levels() {
+		hooks[level] = insertHook(hooks[level], hook)
+	}
+	l.logger.Logger.ReplaceHooks(hooks)
+}
+
+// copyHooks returns a copy of hooks whose slices can be changed without affecting hooks, which
+// other goroutines may be firing.
+func copyHooks(hooks logrus.LevelHooks) logrus.LevelHooks {
+	copied := make(logrus.LevelHooks, len(hooks))
+	for level, levelHooks := range hooks {
+		copied[level] = append([]logrus.Hook(nil), levelHooks...)
+	}
+
+	return copied
+}
+
+// insertHook adds hook to hooks before the ones writing the entries out.
+func insertHook(hooks []logrus.Hook, hook Hook) []logrus.Hook {
+	i := len(hooks)
+	for i > 0 {
+		if _, ok := hooks[i-1].(outputHook); !ok {
+			break
+		}
+		i--
+	}
+
+	hooks = append(hooks, nil)
+	copy(hooks[i+1:], hooks[i:])
+	hooks[i] = hook
+
+	return hooks
+}
@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingHook records the entries fired at its levels.
+type recordingHook struct {
+	levels []logrus.Level
+
+	mu      sync.Mutex
+	entries []*logrus.Entry
+}
+
+func (h *recordingHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *recordingHook) Fire(e *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, e)
+	return nil
+}
+
+func (h *recordingHook) messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var messages []string
+	for _, e := range h.entries {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func TestAddHook(t *testing.T) {
+	logger := New(INFO, io.Discard)
+	hook := &recordingHook{levels: []logrus.Level{logrus.WarnLevel, logrus.ErrorLevel}}
+	logger.AddHook(hook)
+
+	logger.Debugln("debug")
+	logger.Infoln("info")
+	logger.WithFields(map[string]interface{}{"k": "v"}).Warnln("warn")
+	logger.Errorln("error")
+
+	want := []string{"warn", "error"}
+	if got := hook.messages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("fired entries = %v, want %v", got, want)
+	}
+	if got := hook.entries[0].Data["k"]; got != "v" {
+		t.Errorf("field k = %v, want v", got)
+	}
+}
+
+func TestAddHookSharedByDerivedLoggers(t *testing.T) {
+	logger := New(DEBUG, io.Discard)
+	hook := &recordingHook{levels: logrus.AllLevels}
+	logger.WithFields(map[string]interface{}{"k": "v"}).AddHook(hook)
+
+	logger.Infoln("parent")
+
+	if got, want := hook.messages(), []string{"parent"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fired entries = %v, want %v", got, want)
+	}
+}
+
+func TestAddHookBeforeObserver(t *testing.T) {
+	logger, observer := NewObserver(DEBUG)
+	logger.AddHook(fieldHook{key: "hooked", value: "yes"})
+
+	logger.Infoln("info")
+
+	if got := observer.Entries()[0].Fields["hooked"]; got != "yes" {
+		t.Errorf("field hooked = %v, want the one added by the hook", got)
+	}
+}
@@ -0,0 +1,136 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const journaldSocket = "/run/systemd/journal/socket"
+
+// JournaldSink is a Sink sending entries to the systemd journal over its native protocol.
+// Fields are sent as journal fields, their names uppercased with the characters the journal
+// doesn't allow replaced by underscores, see journaldFieldName.
+type JournaldSink struct {
+	identifier string
+	conn       *net.UnixConn
+}
+
+// NewJournaldSink creates a new sink sending entries to the local journal, tagged with the
+// given syslog identifier. An error is returned if the journal socket can't be reached.
+func NewJournaldSink(identifier string) (*JournaldSink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("golog: journald unreachable: %w", err)
+	}
+
+	return &JournaldSink{
+		identifier: identifier,
+		conn:       conn,
+	}, nil
+}
+
+// Write implements Sink.
+func (s *JournaldSink) Write(entry Entry) error {
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "MESSAGE", entry.Message)
+	writeJournaldField(&buf, "PRIORITY", journaldPriority(entry.Level))
+	if s.identifier != "" {
+		writeJournaldField(&buf, "SYSLOG_IDENTIFIER", s.identifier)
+	}
+	for k, v := range entry.Fields {
+		name := journaldFieldName(k)
+		if name == "" {
+			continue
+		}
+		writeJournaldField(&buf, name, journaldValue(v))
+	}
+
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the connection to the journal.
+func (s *JournaldSink) Close() error {
+	return s.conn.Close()
+}
+
+// writeJournaldField writes a field in the native protocol format, values spanning several
+// lines are prefixed by their length.
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journaldFieldPrefix prefixes the field names which would otherwise be invalid or replace
+// the fields set by the sink.
+const journaldFieldPrefix = "FIELD_"
+
+// journaldFieldName converts a field key to a valid journal field name, made of uppercase
+// letters, digits and underscores and not starting with an underscore, reserved to trusted
+// fields, or with a digit. The names starting with a digit or taken by the fields the sink
+// sets, such as the MESSAGE one of a "message" field, are prefixed by FIELD_.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	switch {
+	case name == "":
+		return ""
+	case name[0] >= '0' && name[0] <= '9', name == "MESSAGE", name == "PRIORITY", name == "SYSLOG_IDENTIFIER":
+		return journaldFieldPrefix + name
+	}
+	return name
+}
+
+func journaldValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// journaldPriority maps a level to its syslog priority.
+func journaldPriority(l Level) string {
+	switch l {
+	case DEBUG:
+		return "7"
+	case INFO:
+		return "6"
+	case WARN:
+		return "4"
+	default:
+		return "3"
+	}
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteJournaldField(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "single line", value: "hello", want: "MESSAGE=hello\n"},
+		{name: "empty", value: "", want: "MESSAGE=\n"},
+		{
+			name:  "multiple lines",
+			value: "first\nsecond",
+			want:  "MESSAGE\n\x0c\x00\x00\x00\x00\x00\x00\x00first\nsecond\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeJournaldField(&buf, "MESSAGE", tt.value)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeJournaldField() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "requestId", want: "REQUESTID"},
+		{key: "http.status-code", want: "HTTP_STATUS_CODE"},
+		{key: "_trusted", want: "TRUSTED"},
+		{key: "__", want: ""},
+		{key: "message", want: "FIELD_MESSAGE"},
+		{key: "priority", want: "FIELD_PRIORITY"},
+		{key: "syslog_identifier", want: "FIELD_SYSLOG_IDENTIFIER"},
+		{key: "2fa", want: "FIELD_2FA"},
+		{key: "_1", want: "FIELD_1"},
+	}
+
+	for _, tt := range tests {
+		if got := journaldFieldName(tt.key); got != tt.want {
+			t.Errorf("journaldFieldName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
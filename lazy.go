@@ -0,0 +1,35 @@
+package golog
+
+type lazyField struct {
+	key       string
+	fn        func() interface{}
+	namespace []string
+}
+
+// WithLazyField returns a new logger with a field whose value is computed by fn. fn is only
+// called when an entry is actually emitted, so it costs nothing when the level of the log
+// call is disabled.
+func (l Logger) WithLazyField(key string, fn func() interface{}) Logger {
+	if l.core != nil && l.core.nop {
+		return l
+	}
+
+	lazyFields := make([]lazyField, len(l.lazyFields), len(l.lazyFields)+1)
+	copy(lazyFields, l.lazyFields)
+	l.lazyFields = append(lazyFields, lazyField{key: key, fn: fn, namespace: l.namespace})
+
+	return l
+}
+
+// withLazyFields returns the logger with its lazy fields evaluated, each in the namespace it
+// was added in.
+func (l Logger) withLazyFields() Logger {
+	lazyFields, namespace := l.lazyFields, l.namespace
+	for _, f := range lazyFields {
+		l.namespace = f.namespace
+		l = l.WithFields(map[string]interface{}{f.key: f.fn()})
+	}
+	l.namespace = namespace
+
+	return l
+}
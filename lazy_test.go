@@ -0,0 +1,65 @@
+package golog
+
+import (
+	"io"
+	"testing"
+)
+
+func TestWithLazyField(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+
+	calls := 0
+	lazy := logger.WithLazyField("payload", func() interface{} {
+		calls++
+		return "computed"
+	})
+
+	lazy.Debugln("disabled")
+	if calls != 0 {
+		t.Fatalf("fn called %d times for a disabled level, want 0", calls)
+	}
+
+	lazy.Infoln("enabled")
+	if calls != 1 {
+		t.Fatalf("fn called %d times for an enabled level, want 1", calls)
+	}
+	if got := decodeEntry(t, buf)["payload"]; got != "computed" {
+		t.Errorf("payload = %v, want computed", got)
+	}
+}
+
+func BenchmarkWithLazyFieldDisabled(b *testing.B) {
+	logger := New(INFO, io.Discard)
+
+	calls := 0
+	fn := func() interface{} {
+		calls++
+		return "computed"
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithLazyField("payload", fn).Debugln("disabled")
+	}
+	if calls != 0 {
+		b.Fatalf("fn called %d times for a disabled level, want 0", calls)
+	}
+}
+
+func BenchmarkWithLazyFieldEnabled(b *testing.B) {
+	logger := New(INFO, io.Discard)
+
+	calls := 0
+	fn := func() interface{} {
+		calls++
+		return "computed"
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithLazyField("payload", fn).Infoln("enabled")
+	}
+	if calls != b.N {
+		b.Fatalf("fn called %d times, want %d", calls, b.N)
+	}
+}
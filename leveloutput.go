@@ -0,0 +1,55 @@
+package golog
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithLevelOutput writes the entries at the given level and above to w, os.Stderr if w is
+// nil, and the others to the logger's writer, as in WithLevelOutput(ERROR, os.Stderr).
+// SetOutput only replaces the writer of the others.
+func WithLevelOutput(level Level, w io.Writer) Option {
+	if w == nil {
+		w = os.Stderr
+	}
+
+	return func(o *options) {
+		o.levelOutput = &levelOutput{level: level, writer: w}
+	}
+}
+
+type levelOutput struct {
+	level  Level
+	writer io.Writer
+}
+
+// levelOutputHook writes the entries to the sink of their level.
+type levelOutputHook struct {
+	threshold logrus.Level
+	high      *WriterSink
+	low       *WriterSink
+}
+
+func (h *levelOutputHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *levelOutputHook) Fire(entry *logrus.Entry) error {
+	if entry.Level <= h.threshold {
+		return h.high.write(entry)
+	}
+	return h.low.write(entry)
+}
+
+func (h *levelOutputHook) output() {}
+
+// withWriter returns a hook writing the entries below the threshold to w.
+func (h *levelOutputHook) withWriter(w io.Writer) outputHook {
+	return &levelOutputHook{
+		threshold: h.threshold,
+		high:      h.high,
+		low:       h.low.withWriter(w),
+	}
+}
@@ -0,0 +1,50 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWithLevelOutput(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	logger, stdout := newTestLogger(t, DEBUG, WithLevelOutput(WARN, stderr))
+
+	logger.Debugln("debug")
+	logger.Infoln("info")
+	logger.Warnln("warn")
+	logger.Errorln("error")
+
+	messages := func(buf *bytes.Buffer) []interface{} {
+		var messages []interface{}
+		for _, e := range decodeEntries(t, buf) {
+			messages = append(messages, e["message"])
+		}
+		return messages
+	}
+	if got := messages(stdout); len(got) != 2 || got[0] != "debug" || got[1] != "info" {
+		t.Errorf("logger writer got %v, want debug and info", got)
+	}
+	if got := messages(stderr); len(got) != 2 || got[0] != "warn" || got[1] != "error" {
+		t.Errorf("level writer got %v, want warn and error", got)
+	}
+}
+
+func TestWithLevelOutputSetOutput(t *testing.T) {
+	stderr, stdout := &bytes.Buffer{}, &bytes.Buffer{}
+	logger, replaced := newTestLogger(t, DEBUG, WithLevelOutput(WARN, stderr))
+	logger.AddHook(fieldHook{key: "hooked", value: "yes"})
+	logger.SetOutput(stdout)
+
+	logger.Infoln("info")
+	logger.Errorln("error")
+
+	if replaced.Len() != 0 {
+		t.Errorf("replaced writer got %q, want nothing", replaced.String())
+	}
+	if e := decodeEntry(t, stdout); e["message"] != "info" || e["hooked"] != "yes" {
+		t.Errorf("new writer got %v, want the info entry with the field added by the hook", e)
+	}
+	if e := decodeEntry(t, stderr); e["message"] != "error" || e["hooked"] != "yes" {
+		t.Errorf("level writer got %v, want the error entry with the field added by the hook", e)
+	}
+}
@@ -1,10 +1,12 @@
 package golog
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -31,10 +33,11 @@ func (l Level) String() string {
 }
 
 var lookupMap = map[string]Level{
-	"debug": DEBUG,
-	"info":  INFO,
-	"warn":  WARN,
-	"error": ERROR,
+	"debug":   DEBUG,
+	"info":    INFO,
+	"warn":    WARN,
+	"warning": WARN,
+	"error":   ERROR,
 }
 
 // GetLevel returns the input string's corresponding logging Level
@@ -42,6 +45,34 @@ func GetLevel(s string) Level {
 	return lookupMap[s]
 }
 
+// MarshalText implements encoding.TextMarshaler, so that levels can be written to config
+// files.
+func (l Level) MarshalText() ([]byte, error) {
+	if l < DEBUG || l > ERROR {
+		return nil, fmt.Errorf("golog: invalid level %d", int(l))
+	}
+
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that config structs can have a Level
+// field read from strings such as "info". Unlike GetLevel, it fails on unknown levels.
+func (l *Level) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// Set implements flag.Value, so that levels can be read from the command line with
+// flag.Var(&level, "log-level", "logging level"). It fails on unknown levels.
+func (l *Level) Set(s string) error {
+	level, ok := lookupMap[strings.ToLower(s)]
+	if !ok {
+		return fmt.Errorf("golog: unknown level %q, valid levels are debug, info, warn and error", s)
+	}
+
+	*l = level
+	return nil
+}
+
 func (l Level) toLogrusLevel() logrus.Level {
 	switch l {
 	case DEBUG:
@@ -62,73 +93,540 @@ const (
 	TagKey        = "tag"
 	ErrorKey      = "error"
 	StacktraceKey = "stack_trace" // required by Stackdriver to do error reporting
+	ErrorChainKey = "error_chain"
+	// FieldMarshalErrorKey holds, for each field that couldn't be marshaled to JSON and was
+	// logged as its string representation instead, the marshaling error.
+	FieldMarshalErrorKey = "field_marshal_error"
+	SampledCountKey      = "sampled_count"
+	// InvalidFieldPairsKey holds the trailing argument of a With call given an odd number of
+	// arguments.
+	InvalidFieldPairsKey = "invalid_field_pairs"
+	// OccurrencesKey holds the number of times a deduplicated entry was logged, see WithDedup.
+	OccurrencesKey = "occurrences"
+	// WorkerKey holds the worker identifier of the loggers returned by ForWorker.
+	WorkerKey = "worker"
+	// SuppressedCountKey holds the number of error entries dropped by WithErrorRateLimit.
+	SuppressedCountKey = "suppressed_count"
+	// ComponentKey holds the name of the loggers returned by Named.
+	ComponentKey = "component"
 )
 
 // Logger struct holds the actual 3rd party logger we rely on,
 // decouple the users of this package from the specific 3rd party logging lib we are using
 type Logger struct {
-	logger *logrus.Entry
+	logger     *logrus.Entry
+	core       *core
+	sampleKey  string
+	lazyFields []lazyField
+	namespace  []string
+}
+
+// core holds the state shared by a logger and all the loggers derived from it.
+type core struct {
+	fields         map[string]interface{}
+	sampler        *sampler
+	deduper        *deduper
+	errorLimiter   *rateLimiter
+	stackFormatter func(err error) string
+	fieldProcessor func(key string, value interface{}) (string, interface{})
+	clock          Clock
+	reportCaller   bool
+	goroutineID    bool
+	normalizeTimes bool
+	severityScale  *SeverityScale
+	nop            bool
+
+	mu      sync.Mutex
+	closers []func() error
+}
+
+// Option configures a logger created by NewWithOptions.
+type Option func(*options)
+
+type options struct {
+	samplingRate   int
+	timeKey        string
+	levelKey       string
+	messageKey     string
+	fieldNamesSet  bool
+	fields         map[string]interface{}
+	format         Format
+	color          *bool
+	text           textOptions
+	dedupWindow    time.Duration
+	stackFormatter func(err error) string
+	fieldProcessor func(key string, value interface{}) (string, interface{})
+	clock          Clock
+
+	omitEmptyMessage bool
+	prettyJSON       bool
+	errorRateLimit   int
+	errorRateWindow  time.Duration
+	reportCaller     bool
+	goroutineID      bool
+	levelOutput      *levelOutput
+	normalizeTimes   bool
+	severityScale    *SeverityScale
 }
 
-// New creates a new logger
+// WithFieldNames overrides the names of the timestamp, level and message fields, which
+// default to "timestamp", "severity" and "message" as expected by Stackdriver. An empty
+// name keeps the default. NewWithOptions rejects it with FormatGCP.
+func WithFieldNames(time, level, message string) Option {
+	return func(o *options) {
+		o.fieldNamesSet = true
+		if time != "" {
+			o.timeKey = time
+		}
+		if level != "" {
+			o.levelKey = level
+		}
+		if message != "" {
+			o.messageKey = message
+		}
+	}
+}
+
+// WithSampling only emits every rate-th message of each level, see Logger.WithSampleKey to
+// sample by a key instead. Emitted entries carry the number of suppressed ones under
+// SampledCountKey. A rate of 1 or less disables sampling.
+func WithSampling(rate int) Option {
+	return func(o *options) {
+		o.samplingRate = rate
+	}
+}
+
+// WithDefaultFields attaches fields to every entry of the logger and of the loggers derived
+// from it. A field set later through WithFields takes precedence over a default one.
+func WithDefaultFields(fields map[string]interface{}) Option {
+	return func(o *options) {
+		if o.fields == nil {
+			o.fields = make(map[string]interface{}, len(fields))
+		}
+		for k, v := range fields {
+			o.fields[k] = v
+		}
+	}
+}
+
+// WithStackFormatter sets the function formatting the stacktrace of errors logged under
+// ErrorKey, for error libraries that don't print their stack with the %+v verb. It defaults
+// to fmt.Sprintf("%+v", err).
+func WithStackFormatter(fn func(err error) string) Option {
+	return func(o *options) {
+		o.stackFormatter = fn
+	}
+}
+
+// New creates a new logger writing to o, or to os.Stdout if o is nil
 func New(l Level, o io.Writer) Logger {
+	return newLogger(l, o, newOptions(nil))
+}
+
+// NewWithOptions creates a new logger configured by the given options, writing to o or to
+// os.Stdout if o is nil. An error is returned if the options conflict, such as FormatGCP
+// with WithFieldNames or options of FormatText with another format.
+func NewWithOptions(l Level, o io.Writer, opts ...Option) (Logger, error) {
+	options := newOptions(opts)
+	if err := options.validate(); err != nil {
+		return Logger{}, err
+	}
+
+	return newLogger(l, o, options), nil
+}
+
+// MustNewWithOptions is like NewWithOptions but panics if the options conflict, for options
+// known to be valid.
+func MustNewWithOptions(l Level, o io.Writer, opts ...Option) Logger {
+	logger, err := NewWithOptions(l, o, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return logger
+}
+
+func newLogger(l Level, o io.Writer, options options) Logger {
+	if o == nil {
+		o = os.Stdout
+	}
+
+	// the writer is the default sink
+	writer := &WriterSink{writer: o, formatter: newFormatter(options, o)}
+	if options.levelOutput != nil {
+		return newOutputLogger(l, options, &levelOutputHook{
+			threshold: options.levelOutput.level.toLogrusLevel(),
+			high:      writer.withWriter(options.levelOutput.writer),
+			low:       writer,
+		})
+	}
+
+	return newOutputLogger(l, options, &writerHook{sinkHook{sink: writer}})
+}
+
+// newOutputLogger creates a new logger whose entries are written out by the given hooks.
+func newOutputLogger(l Level, options options, outputs ...outputHook) Logger {
 	logger := logrus.New()
-	logger.Formatter = &logrus.JSONFormatter{
-		FieldMap: logrus.FieldMap{
-			logrus.FieldKeyTime:  "timestamp",
-			logrus.FieldKeyLevel: "severity",
-			logrus.FieldKeyMsg:   "message",
+	logger.Formatter = discardFormatter{}
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(l.toLogrusLevel())
+	for _, output := range outputs {
+		logger.AddHook(output)
+	}
+
+	return Logger{
+		logger: logrus.NewEntry(logger).WithFields(options.fields),
+		core: &core{
+			fields:         options.fields,
+			sampler:        newSampler(options.samplingRate),
+			deduper:        newDeduper(options.dedupWindow),
+			errorLimiter:   newRateLimiter(options.errorRateLimit, options.errorRateWindow),
+			stackFormatter: options.stackFormatter,
+			fieldProcessor: options.fieldProcessor,
+			clock:          options.clock,
+			reportCaller:   options.reportCaller,
+			goroutineID:    options.goroutineID,
+			normalizeTimes: options.normalizeTimes,
+			severityScale:  options.severityScale,
 		},
-		TimestampFormat: time.RFC3339Nano,
 	}
+}
 
-	logger.SetLevel(l.toLogrusLevel())
-	logger.SetOutput(o)
+// newOptions returns the default options overridden by opts.
+func newOptions(opts []Option) options {
+	options := options{
+		timeKey:    "timestamp",
+		levelKey:   "severity",
+		messageKey: "message",
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// validate returns an error describing the first conflict between the options.
+func (o options) validate() error {
+	textOptionsSet := o.color != nil || o.text != textOptions{}
+	switch {
+	case o.format == FormatGCP && o.fieldNamesSet:
+		return errors.New("golog: WithFieldNames can't be used with FormatGCP, which names the fields as Cloud Logging expects")
+	case o.format != FormatText && textOptionsSet:
+		return errors.New("golog: WithColor, WithDisableTimestamp, WithFullTimestamp and WithPadLevelText require FormatText")
+	case o.format == FormatText && o.prettyJSON:
+		return errors.New("golog: WithPrettyJSON can't be used with FormatText")
+	default:
+		return nil
+	}
+}
+
+// NewNop creates a new logger that discards everything. Deriving loggers from it with
+// WithFields is free as well.
+func NewNop() Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.PanicLevel)
 
 	return Logger{
 		logger: logrus.NewEntry(logger),
+		core:   &core{nop: true},
+	}
+}
+
+// nop is the logger returned by LogIf when its condition is false.
+var nop = NewNop()
+
+// LogIf returns the logger if cond holds, or else a logger discarding everything, so that
+// call sites can log conditionally as in logger.LogIf(debugTenant).Debugln(msg). Fields
+// added to the discarding logger aren't copied.
+func (l Logger) LogIf(cond bool) Logger {
+	if cond {
+		return l
 	}
+
+	return nop
 }
 
+// DefaultLevelEnvKeys are the env variables NewDefault reads the level from, in order of
+// priority.
+var DefaultLevelEnvKeys = []string{"LOGGING_LEVEL", "LOG_LEVEL"}
+
 // NewDefault creates a new logger with default level configured in env variable,
-// if not set, default to debug
+// if not set, default to debug. See DefaultLevelEnvKeys for the variables read.
 func NewDefault() Logger {
-	return New(GetLevel(getEnv("LOGGING_LEVEL", "debug")), os.Stdout)
+	return NewFromEnv(DefaultLevelEnvKeys...)
+}
+
+// NewFromEnv creates a new logger with the level configured in the first of the given env
+// variables that is set, if none is set, default to debug. An unknown level falls back to
+// info, and a warning naming the variable is logged.
+func NewFromEnv(keys ...string) Logger {
+	key, value, ok := lookupEnv(keys)
+	if !ok {
+		return New(DEBUG, os.Stdout)
+	}
+
+	level, known := lookupMap[value]
+	if !known {
+		logger := New(INFO, os.Stdout)
+		logger.WithFields(map[string]interface{}{
+			"env":   key,
+			"value": value,
+		}).Warnln("unknown logging level, falling back to info")
+		return logger
+	}
+
+	return New(level, os.Stdout)
 }
 
-func getEnv(key string, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return strings.ToLower(value)
+// lookupEnv returns the first of the given env variables that is set, and its lowercased
+// value.
+func lookupEnv(keys []string) (string, string, bool) {
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			return key, strings.ToLower(value), true
+		}
 	}
 
-	return fallback
+	return "", "", false
+}
+
+// IsZero reports whether l is the zero Logger, which isn't usable. Loggers must be created
+// with New or one of its variants.
+func (l Logger) IsZero() bool {
+	return l.logger == nil
+}
+
+// IsLevelEnabled reports whether entries at the given level are emitted, so that callers can
+// skip building expensive fields for entries that would be discarded. It is safe to call
+// while another goroutine changes the level with SetLevel.
+func (l Logger) IsLevelEnabled(level Level) bool {
+	return l.logger.Logger.IsLevelEnabled(level.toLogrusLevel())
+}
+
+// DebugEnabled reports whether debug entries are emitted.
+func (l Logger) DebugEnabled() bool {
+	return l.IsLevelEnabled(DEBUG)
+}
+
+// InfoEnabled reports whether info entries are emitted.
+func (l Logger) InfoEnabled() bool {
+	return l.IsLevelEnabled(INFO)
+}
+
+// WarnEnabled reports whether warning entries are emitted.
+func (l Logger) WarnEnabled() bool {
+	return l.IsLevelEnabled(WARN)
+}
+
+// ErrorEnabled reports whether error entries are emitted.
+func (l Logger) ErrorEnabled() bool {
+	return l.IsLevelEnabled(ERROR)
 }
 
 func (l Logger) Debugln(msg string) {
-	l.logger.Logln(logrus.DebugLevel, msg)
+	l.log(logrus.DebugLevel, msg)
 }
 
 func (l Logger) Infoln(msg string) {
-	l.logger.Logln(logrus.InfoLevel, msg)
+	l.log(logrus.InfoLevel, msg)
 }
 
 func (l Logger) Warnln(msg string) {
-	l.logger.Logln(logrus.WarnLevel, msg)
+	l.log(logrus.WarnLevel, msg)
 }
 
 func (l Logger) Errorln(msg string) {
-	l.logger.Logln(logrus.ErrorLevel, msg)
+	l.log(logrus.ErrorLevel, msg)
+}
+
+// Logln logs the message at the given level.
+func (l Logger) Logln(level Level, msg string) {
+	l.log(level.toLogrusLevel(), msg)
+}
+
+// Logf formats the message according to the format specifier and logs it at the given level.
+func (l Logger) Logf(level Level, format string, args ...interface{}) {
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	l.log(level.toLogrusLevel(), fmt.Sprintf(format, args...))
+}
+
+// log is the single path every entry goes through before being handed to logrus.
+func (l Logger) log(level logrus.Level, msg string) {
+	if !l.logger.Logger.IsLevelEnabled(level) {
+		return
+	}
+
+	if level <= logrus.ErrorLevel && l.core != nil && l.core.errorLimiter != nil {
+		if !l.core.errorLimiter.allow(l.errorSummary()) {
+			return
+		}
+	}
+
+	if l.core != nil && l.core.sampler != nil {
+		key := l.sampleKey
+		if key == "" {
+			key = level.String()
+		}
+		suppressed, ok := l.core.sampler.sample(key)
+		if !ok {
+			return
+		}
+		if suppressed > 0 {
+			l.logger = l.logger.WithField(SampledCountKey, suppressed)
+		}
+	}
+	if len(l.lazyFields) > 0 {
+		l = l.withLazyFields()
+	}
+	if l.core != nil && l.core.reportCaller {
+		l.logger = l.logger.WithField(CallerKey, caller())
+	}
+	if l.core != nil && l.core.goroutineID {
+		l.logger = l.logger.WithField(GoroutineIDKey, goroutineID())
+	}
+	if l.core != nil && l.core.severityScale != nil {
+		l.logger = l.logger.WithField(SeverityNumberKey, l.core.severityScale.severityNumber(fromLogrusLevel(level)))
+	}
+	if l.core != nil && l.core.normalizeTimes {
+		l.logger = processFields(l.logger, normalizeTimes)
+	}
+	if l.core != nil && l.core.fieldProcessor != nil {
+		l.logger = processFields(l.logger, l.core.fieldProcessor)
+	}
+	if l.core != nil && l.core.deduper != nil {
+		entry, logger := l.logger, l
+		summary := func(occurrences int) {
+			logger.timestamped(entry.WithField(OccurrencesKey, occurrences)).Logln(level, msg)
+		}
+		if !l.core.deduper.admit(dedupKey(level, msg, entry.Data), summary) {
+			return
+		}
+	}
+
+	l.timestamped(l.logger).Logln(level, msg)
 }
 
 // WithFields returns a new logger with key value pairs added. Calling this method doesn't
 // log anything. Caller has to call Debugln, Infoln, Warnln or Errorln to flush the key value
-// pair into a log entry.
+// pair into a log entry. The fields map isn't modified and can be reused by the caller.
 func (l Logger) WithFields(fields map[string]interface{}) Logger {
+	if len(fields) == 0 || l.core != nil && l.core.nop {
+		return l
+	}
+
+	// the caller's map may be reused or shared across goroutines, it is copied before being
+	// modified and logrus copies it otherwise
 	if val, ok := fields[ErrorKey]; ok {
+		fields = copyFields(fields, 2)
 		fields[StacktraceKey] = fmt.Sprintf("%+v", val)
+		if err, ok := val.(error); ok {
+			if l.core != nil && l.core.stackFormatter != nil {
+				fields[StacktraceKey] = l.core.stackFormatter(err)
+			}
+			fields[ErrorChainKey] = errorChain(err)
+		}
+	}
+	fields = sanitizeFields(fields)
+	if len(l.namespace) > 0 {
+		fields = l.nestFields(fields)
 	}
 
-	return Logger{
-		logger: l.logger.WithFields(fields),
+	l.logger = l.logger.WithFields(fields)
+	return l
+}
+
+// copyFields returns a copy of fields with room for extra more entries.
+func copyFields(fields map[string]interface{}, extra int) map[string]interface{} {
+	copied := make(map[string]interface{}, len(fields)+extra)
+	for k, v := range fields {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+// With returns a new logger with fields added from alternating key value arguments, such as
+// With("user", id, "attempt", 2). Keys that aren't strings are converted with fmt.Sprint and
+// a trailing key without a value is logged under InvalidFieldPairsKey.
+func (l Logger) With(args ...interface{}) Logger {
+	fields := make(map[string]interface{}, (len(args)+1)/2)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fields[InvalidFieldPairsKey] = args[i]
+			break
+		}
+
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprint(args[i])
+		}
+		fields[key] = args[i+1]
+	}
+
+	return l.WithFields(fields)
+}
+
+// WithError returns a new logger with the error added under ErrorKey, along with its
+// stacktrace and the chain of wrapped errors.
+func (l Logger) WithError(err error) Logger {
+	return l.WithFields(map[string]interface{}{ErrorKey: err})
+}
+
+// ForWorker returns a new logger identifying a worker goroutine under WorkerKey, for work
+// fanned out from a request, as in GetLogger(ctx).ForWorker(id). The fields of the parent
+// logger, such as the request ID, are kept.
+func (l Logger) ForWorker(id string) Logger {
+	return l.WithFields(map[string]interface{}{WorkerKey: id})
+}
+
+// Named returns a new logger tagging its entries with the component name under ComponentKey,
+// such as "db". Names given to a named logger are joined with a dot, so that
+// logger.Named("db").Named("pool") logs "db.pool". The field isn't nested under the namespace
+// set by WithNamespace.
+func (l Logger) Named(name string) Logger {
+	if l.core != nil && l.core.nop {
+		return l
 	}
+
+	if parent, ok := l.logger.Data[ComponentKey].(string); ok && parent != "" {
+		name = parent + "." + name
+	}
+	l.logger = l.logger.WithField(ComponentKey, name)
+	return l
+}
+
+// WithTag returns a new logger adding tag to the tags of its entries, logged as a list under
+// TagKey, so that logger.WithTag("auth").WithTag("db") logs ["auth","db"]. Like Named, the
+// field isn't nested under the namespace set by WithNamespace.
+func (l Logger) WithTag(tag string) Logger {
+	if l.core != nil && l.core.nop {
+		return l
+	}
+
+	parent, _ := l.logger.Data[TagKey].([]string)
+	tags := make([]string, len(parent), len(parent)+1)
+	copy(tags, parent)
+	l.logger = l.logger.WithField(TagKey, append(tags, tag))
+	return l
+}
+
+// errorChain returns the message of err and of every error it wraps, outermost first.
+func errorChain(err error) []string {
+	var chain []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err.Error())
+	}
+
+	return chain
+}
+
+// Entry returns the underlying logrus entry for features golog doesn't wrap. Logging through
+// it bypasses golog's conventions, such as the stacktrace handling of WithFields and the
+// sampling option, so use it deliberately.
+func (l Logger) Entry() *logrus.Entry {
+	return l.logger
 }
@@ -0,0 +1,499 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestLogger returns a logger writing JSON entries to the returned buffer.
+func newTestLogger(t *testing.T, l Level, opts ...Option) (Logger, *bytes.Buffer) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	logger, err := NewWithOptions(l, buf, opts...)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	return logger, buf
+}
+
+// decodeEntries decodes the JSON entries written to buf, one per line.
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSON entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// decodeEntry decodes the single JSON entry written to buf.
+func decodeEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %q", len(entries), buf.String())
+	}
+
+	return entries[0]
+}
+
+func TestWithErrorChain(t *testing.T) {
+	root := errors.New("connection refused")
+	tests := []struct {
+		name      string
+		err       error
+		wantChain []interface{}
+	}{
+		{
+			name:      "unwrapped",
+			err:       root,
+			wantChain: []interface{}{"connection refused"},
+		},
+		{
+			name: "wrapped twice",
+			err:  fmt.Errorf("load user: %w", fmt.Errorf("query: %w", root)),
+			wantChain: []interface{}{
+				"load user: query: connection refused",
+				"query: connection refused",
+				"connection refused",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger(t, INFO)
+			logger.WithError(tt.err).Errorln("failed")
+
+			entry := decodeEntry(t, buf)
+			if got := entry[ErrorChainKey]; !reflect.DeepEqual(got, tt.wantChain) {
+				t.Errorf("%s = %v, want %v", ErrorChainKey, got, tt.wantChain)
+			}
+			if got := entry[StacktraceKey]; got != tt.err.Error() {
+				t.Errorf("%s = %v, want %q", StacktraceKey, got, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestWithDefaultFields(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO, WithDefaultFields(map[string]interface{}{
+		"service": "billing",
+		"env":     "prod",
+	}))
+
+	logger.Infoln("base")
+	logger.WithFields(map[string]interface{}{"request": 1}).Infoln("derived")
+	logger.WithFields(map[string]interface{}{"env": "staging"}).Infoln("override")
+	logger.Infoln("base again")
+
+	tests := []struct {
+		message string
+		want    map[string]interface{}
+	}{
+		{"base", map[string]interface{}{"service": "billing", "env": "prod"}},
+		{"derived", map[string]interface{}{"service": "billing", "env": "prod", "request": float64(1)}},
+		{"override", map[string]interface{}{"service": "billing", "env": "staging"}},
+		{"base again", map[string]interface{}{"service": "billing", "env": "prod"}},
+	}
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		entry := entries[i]
+		if entry["message"] != tt.message {
+			t.Fatalf("entry %d message = %v, want %q", i, entry["message"], tt.message)
+		}
+		for k, want := range tt.want {
+			if got := entry[k]; got != want {
+				t.Errorf("%q: field %s = %v, want %v", tt.message, k, got, want)
+			}
+		}
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(out)
+}
+
+func TestNewNilWriter(t *testing.T) {
+	out := captureStdout(t, func() {
+		New(INFO, nil).Infoln("to stdout")
+	})
+
+	if !strings.Contains(out, `"message":"to stdout"`) {
+		t.Errorf("stdout = %q, want the entry", out)
+	}
+}
+
+func TestNewNopAllocations(t *testing.T) {
+	logger := NewNop()
+	fields := map[string]interface{}{"user": "u1", "attempt": 2}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.WithFields(fields).Errorln("discarded")
+	})
+	if allocs != 0 {
+		t.Errorf("allocations = %v, want 0", allocs)
+	}
+}
+
+func BenchmarkNewNop(b *testing.B) {
+	logger := NewNop()
+	fields := map[string]interface{}{"user": "u1", "attempt": 2}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(fields).Errorln("discarded")
+	}
+}
+
+// BenchmarkDiscard is the baseline NewNop improves on.
+func BenchmarkDiscard(b *testing.B) {
+	logger := New(ERROR, io.Discard)
+	fields := map[string]interface{}{"user": "u1", "attempt": 2}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(fields).Errorln("discarded")
+	}
+}
+
+func TestWithFieldsSharedMap(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	err := errors.New("failed")
+	fields := map[string]interface{}{ErrorKey: err, "user": "u1"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				logger.WithFields(fields).WithFields(map[string]interface{}{"goroutine": i}).Errorln("shared")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	want := map[string]interface{}{ErrorKey: err, "user": "u1"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want them unmodified %v", fields, want)
+	}
+	for _, entry := range decodeEntries(t, buf) {
+		if entry[StacktraceKey] != "failed" {
+			t.Fatalf("%s = %v, want failed", StacktraceKey, entry[StacktraceKey])
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		name string
+		args []interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "even pairs",
+			args: []interface{}{"user", "u1", "attempt", 2},
+			want: map[string]interface{}{"user": "u1", "attempt": float64(2)},
+		},
+		{
+			name: "odd pairs",
+			args: []interface{}{"user", "u1", "dangling"},
+			want: map[string]interface{}{"user": "u1", InvalidFieldPairsKey: "dangling"},
+		},
+		{
+			name: "non-string keys",
+			args: []interface{}{42, "answer", true, "yes"},
+			want: map[string]interface{}{"42": "answer", "true": "yes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger(t, INFO)
+			logger.With(tt.args...).Infoln("with")
+
+			entry := decodeEntry(t, buf)
+			for k, want := range tt.want {
+				if got := entry[k]; got != want {
+					t.Errorf("%s = %v, want %v", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLevelEnabled(t *testing.T) {
+	levels := []Level{DEBUG, INFO, WARN, ERROR}
+	for _, configured := range levels {
+		logger := New(configured, io.Discard)
+		for _, level := range levels {
+			if got, want := logger.IsLevelEnabled(level), level >= configured; got != want {
+				t.Errorf("New(%v).IsLevelEnabled(%v) = %v, want %v", configured, level, got, want)
+			}
+		}
+
+		enabled := []bool{logger.DebugEnabled(), logger.InfoEnabled(), logger.WarnEnabled(), logger.ErrorEnabled()}
+		for i, got := range enabled {
+			if want := levels[i] >= configured; got != want {
+				t.Errorf("New(%v) %v enabled = %v, want %v", configured, levels[i], got, want)
+			}
+		}
+	}
+}
+
+// unsetenv unsets the env variable key for the duration of the test.
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+
+	if value, ok := os.LookupEnv(key); ok {
+		os.Unsetenv(key)
+		t.Cleanup(func() { os.Setenv(key, value) })
+	}
+}
+
+// levelOf returns the level of l.
+func levelOf(l Logger) Level {
+	for _, level := range []Level{DEBUG, INFO, WARN} {
+		if l.IsLevelEnabled(level) {
+			return level
+		}
+	}
+	return ERROR
+}
+
+func TestNewFromEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   string
+		secondary string
+		want      Level
+	}{
+		{name: "primary", primary: "error", secondary: "info", want: ERROR},
+		{name: "secondary", secondary: "warn", want: WARN},
+		{name: "none", want: DEBUG},
+		{name: "uppercase", primary: "INFO", want: INFO},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range map[string]string{"GOLOG_TEST_PRIMARY": tt.primary, "GOLOG_TEST_SECONDARY": tt.secondary} {
+				if value == "" {
+					unsetenv(t, key)
+				} else {
+					t.Setenv(key, value)
+				}
+			}
+
+			if got := levelOf(NewFromEnv("GOLOG_TEST_PRIMARY", "GOLOG_TEST_SECONDARY")); got != tt.want {
+				t.Errorf("level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDefaultLevelEnvKeys(t *testing.T) {
+	t.Run("LOGGING_LEVEL first", func(t *testing.T) {
+		t.Setenv("LOGGING_LEVEL", "error")
+		t.Setenv("LOG_LEVEL", "info")
+		if got := levelOf(NewDefault()); got != ERROR {
+			t.Errorf("level = %v, want %v", got, ERROR)
+		}
+	})
+	t.Run("LOG_LEVEL fallback", func(t *testing.T) {
+		unsetenv(t, "LOGGING_LEVEL")
+		t.Setenv("LOG_LEVEL", "warn")
+		if got := levelOf(NewDefault()); got != WARN {
+			t.Errorf("level = %v, want %v", got, WARN)
+		}
+	})
+}
+
+func TestNewFromEnvUnknownLevel(t *testing.T) {
+	t.Setenv("GOLOG_TEST_LEVEL", "infoo")
+
+	var logger Logger
+	out := captureStdout(t, func() {
+		logger = NewFromEnv("GOLOG_TEST_LEVEL")
+	})
+
+	if got := levelOf(logger); got != INFO {
+		t.Errorf("level = %v, want %v", got, INFO)
+	}
+	buf := bytes.NewBufferString(out)
+	entry := decodeEntry(t, buf)
+	want := map[string]interface{}{
+		"severity": "warning",
+		"message":  "unknown logging level, falling back to info",
+		"env":      "GOLOG_TEST_LEVEL",
+		"value":    "infoo",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v", k, entry[k], v)
+		}
+	}
+}
+
+func TestLoglnLogf(t *testing.T) {
+	for _, level := range []Level{DEBUG, INFO, WARN, ERROR} {
+		t.Run(level.String(), func(t *testing.T) {
+			logger, buf := newTestLogger(t, DEBUG)
+			logger.Logln(level, "line")
+			logger.Logf(level, "formatted %d", 1)
+
+			entries := decodeEntries(t, buf)
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+			for i, want := range []string{"line", "formatted 1"} {
+				if entries[i]["message"] != want {
+					t.Errorf("message = %v, want %q", entries[i]["message"], want)
+				}
+				if entries[i]["severity"] != level.String() {
+					t.Errorf("severity = %v, want %q", entries[i]["severity"], level.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLoglnLogfDisabledLevel(t *testing.T) {
+	logger, buf := newTestLogger(t, WARN)
+	logger.Logln(INFO, "line")
+	logger.Logf(DEBUG, "formatted %d", 1)
+
+	if buf.Len() != 0 {
+		t.Errorf("disabled levels logged: %q", buf.String())
+	}
+}
+
+func TestLogIf(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG)
+
+	logger.LogIf(false).WithFields(map[string]interface{}{"k": "v"}).Errorln("skipped")
+	if buf.Len() != 0 {
+		t.Fatalf("LogIf(false) logged: %q", buf.String())
+	}
+
+	logger.LogIf(true).Debugln("logged")
+	if got := decodeEntry(t, buf)["message"]; got != "logged" {
+		t.Errorf("message = %v, want logged", got)
+	}
+}
+
+func BenchmarkInfolnNoFields(b *testing.B) {
+	logger := New(INFO, io.Discard)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Infoln("no fields")
+	}
+}
+
+func BenchmarkInfolnSevenFields(b *testing.B) {
+	logger := New(INFO, io.Discard)
+	fields := map[string]interface{}{
+		"remoteAddr": "192.0.2.1:1234",
+		"protocol":   "HTTP/1.1",
+		"method":     "GET",
+		"uri":        "/users/1",
+		"userAgent":  "bench",
+		"status":     200,
+		"api":        "GET_/users/1",
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(fields).Infoln("seven fields")
+	}
+}
+
+func TestNamed(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	db := logger.WithFields(map[string]interface{}{"k": "v"}).Named("db")
+	pool := db.Named("pool").WithFields(map[string]interface{}{"size": 4})
+
+	pool.Infoln("pool")
+	db.Infoln("db")
+	logger.Infoln("root")
+	logger.WithNamespace("ns").Named("cache").Infoln("namespaced")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+	if entries[0][ComponentKey] != "db.pool" || entries[0]["k"] != "v" || entries[0]["size"] != 4.0 {
+		t.Errorf("pool entry = %v, want db.pool with the fields of its parents", entries[0])
+	}
+	if entries[1][ComponentKey] != "db" || entries[1]["size"] != nil {
+		t.Errorf("db entry = %v, want db without the fields of its child", entries[1])
+	}
+	if _, ok := entries[2][ComponentKey]; ok {
+		t.Errorf("root entry = %v, want no component", entries[2])
+	}
+	if entries[3][ComponentKey] != "cache" {
+		t.Errorf("namespaced entry = %v, want the component at the top level", entries[3])
+	}
+}
+
+func TestWithTag(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	auth := logger.WithTag("auth")
+
+	auth.Infoln("single")
+	auth.WithTag("db").WithTag("slow").Infoln("multiple")
+	auth.WithTag("cache").Infoln("sibling")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	want := [][]interface{}{{"auth"}, {"auth", "db", "slow"}, {"auth", "cache"}}
+	for i, e := range entries {
+		if !reflect.DeepEqual(e[TagKey], want[i]) {
+			t.Errorf("%s entry: %s = %v, want %v", e["message"], TagKey, e[TagKey], want[i])
+		}
+	}
+}
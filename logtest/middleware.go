@@ -0,0 +1,75 @@
+package logtest
+
+import (
+	"net/http"
+
+	"github.com/cvemprala/golog"
+)
+
+// Capture records the entries logged by a middleware created by CaptureMiddleware. It is safe
+// for concurrent use.
+type Capture struct {
+	observer        *golog.Observer
+	requestMessage  string
+	responseMessage string
+}
+
+// CaptureMiddleware wraps handler in the logging middleware created by golog.NewMiddleware,
+// logging at DEBUG level to the returned Capture instead of an output.
+func CaptureMiddleware(handler http.Handler) (http.Handler, *Capture) {
+	return CaptureMiddlewareWithOptions(handler, golog.MiddlewareOptions{
+		LogResponse: true,
+	})
+}
+
+// CaptureMiddlewareWithOptions is CaptureMiddleware with the middleware created by
+// golog.NewMiddlewareWithOptions.
+func CaptureMiddlewareWithOptions(handler http.Handler, options golog.MiddlewareOptions) (http.Handler, *Capture) {
+	logger, observer := golog.NewObserver(golog.DEBUG)
+	capture := &Capture{
+		observer:        observer,
+		requestMessage:  messageOrDefault(options.RequestMessage, "http_request"),
+		responseMessage: messageOrDefault(options.ResponseMessage, "http_response"),
+	}
+
+	return golog.NewMiddlewareWithOptions(handler, logger, options), capture
+}
+
+// Entries returns all the entries logged so far, in the order they were logged. They include
+// the ones logged by the handler with the request logger.
+func (c *Capture) Entries() []golog.Entry {
+	return c.observer.Entries()
+}
+
+// Requests returns the request entries logged so far.
+func (c *Capture) Requests() []golog.Entry {
+	return c.withMessage(c.requestMessage)
+}
+
+// Responses returns the response entries logged so far.
+func (c *Capture) Responses() []golog.Entry {
+	return c.withMessage(c.responseMessage)
+}
+
+// Reset discards the entries logged so far.
+func (c *Capture) Reset() {
+	c.observer.Reset()
+}
+
+func (c *Capture) withMessage(msg string) []golog.Entry {
+	var entries []golog.Entry
+	for _, e := range c.observer.Entries() {
+		if e.Message == msg {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+func messageOrDefault(msg, defaultMsg string) string {
+	if msg == "" {
+		return defaultMsg
+	}
+	return msg
+}
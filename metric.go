@@ -0,0 +1,82 @@
+package golog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EMFKey holds the CloudWatch Embedded Metric Format metadata added by WithMetric.
+const EMFKey = "_aws"
+
+type emfMetadata struct {
+	now        func() time.Time
+	directives []emfDirective
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit,omitempty"`
+}
+
+// MarshalJSON stamps the metadata with the time the entry is formatted.
+func (m emfMetadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Timestamp         int64          `json:"Timestamp"`
+		CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+	}{
+		Timestamp:         m.now().UnixNano() / int64(time.Millisecond),
+		CloudWatchMetrics: m.directives,
+	})
+}
+
+// WithMetric returns a new logger whose entries carry a CloudWatch metric in the Embedded
+// Metric Format, so that CloudWatch Logs extracts it from the entry. The value is logged as a
+// top-level field named after the metric, and the metric is declared in the EMFKey metadata
+// along with the other metrics of the logger. unit is a CloudWatch unit such as
+// "Milliseconds" or "Count", it can be empty. Namespaces set by WithNamespace don't apply,
+// CloudWatch requires these fields at the top level.
+func (l Logger) WithMetric(namespace, name string, value float64, unit string) Logger {
+	if l.core != nil && l.core.nop {
+		return l
+	}
+
+	metadata := emfMetadata{now: l.now}
+	if existing, ok := l.logger.Data[EMFKey].(emfMetadata); ok {
+		metadata.directives = make([]emfDirective, len(existing.directives))
+		copy(metadata.directives, existing.directives)
+	}
+
+	metric := emfMetric{Name: name, Unit: unit}
+	added := false
+	for i, directive := range metadata.directives {
+		if directive.Namespace == namespace {
+			// the metrics are shared with the logger the directive was copied from
+			metrics := make([]emfMetric, len(directive.Metrics), len(directive.Metrics)+1)
+			copy(metrics, directive.Metrics)
+			metadata.directives[i].Metrics = append(metrics, metric)
+			added = true
+			break
+		}
+	}
+	if !added {
+		metadata.directives = append(metadata.directives, emfDirective{
+			Namespace:  namespace,
+			Dimensions: [][]string{{}},
+			Metrics:    []emfMetric{metric},
+		})
+	}
+
+	l.logger = l.logger.WithFields(logrus.Fields{
+		EMFKey: metadata,
+		name:   value,
+	})
+	return l
+}
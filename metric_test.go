@@ -0,0 +1,62 @@
+package golog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithMetric(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	logger.WithMetric("app", "latency", 12.5, "Milliseconds").
+		WithMetric("app", "requests", 1, "Count").
+		WithMetric("billing", "charges", 3, "").
+		Infoln("metrics")
+
+	entry := decodeEntry(t, buf)
+	if entry["latency"] != 12.5 || entry["requests"] != 1.0 || entry["charges"] != 3.0 {
+		t.Errorf("metric values missing in %v", entry)
+	}
+
+	emf, _ := entry[EMFKey].(map[string]interface{})
+	timestamp, _ := emf["Timestamp"].(float64)
+	if int64(timestamp) < before || int64(timestamp) > time.Now().UnixNano()/int64(time.Millisecond) {
+		t.Errorf("Timestamp = %v, want the time of the entry in milliseconds", emf["Timestamp"])
+	}
+
+	b, _ := json.Marshal(emf["CloudWatchMetrics"])
+	want := `[` +
+		`{"Namespace":"app","Dimensions":[[]],"Metrics":[{"Name":"latency","Unit":"Milliseconds"},{"Name":"requests","Unit":"Count"}]},` +
+		`{"Namespace":"billing","Dimensions":[[]],"Metrics":[{"Name":"charges"}]}` +
+		`]`
+	var got, wantValue interface{}
+	json.Unmarshal(b, &got)
+	json.Unmarshal([]byte(want), &wantValue)
+	if !reflect.DeepEqual(got, wantValue) {
+		t.Errorf("CloudWatchMetrics = %s, want %s", b, want)
+	}
+}
+
+func TestWithMetricSiblings(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	base := logger.WithMetric("app", "latency", 1, "Milliseconds")
+
+	base.WithMetric("app", "requests", 1, "Count")
+	base.WithMetric("app", "errors", 1, "Count").Infoln("sibling")
+
+	entry := decodeEntry(t, buf)
+	if _, ok := entry["requests"]; ok {
+		t.Errorf("requests of a sibling logger present in %v", entry)
+	}
+	directives, _ := entry[EMFKey].(map[string]interface{})["CloudWatchMetrics"].([]interface{})
+	if len(directives) != 1 {
+		t.Fatalf("got %d directives, want 1", len(directives))
+	}
+	metrics, _ := directives[0].(map[string]interface{})["Metrics"].([]interface{})
+	if len(metrics) != 2 {
+		t.Errorf("Metrics = %v, want latency and errors only", metrics)
+	}
+}
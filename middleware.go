@@ -1,16 +1,17 @@
 package golog
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"hash/fnv"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // ResponseWriterRecorder wraps the http.ResponseWriter to order to retrieve information from the response
@@ -20,6 +21,8 @@ type ResponseWriterRecorder struct {
 	body           []byte
 	responseWriter http.ResponseWriter
 	isStatusSet    bool
+	firstWrite     time.Time
+	now            func() time.Time
 }
 
 // NewResponseWriterRecorder creates a new ResponseWriterRecorder wrapping the underlying
@@ -28,6 +31,7 @@ func NewResponseWriterRecorder(w http.ResponseWriter) *ResponseWriterRecorder {
 	return &ResponseWriterRecorder{
 		status:         200,
 		responseWriter: w,
+		now:            time.Now,
 	}
 }
 
@@ -46,8 +50,17 @@ func (r *ResponseWriterRecorder) Header() http.Header {
 	return r.responseWriter.Header()
 }
 
+// FirstWrite returns the time the response started being written, or the zero time if
+// nothing was written yet.
+func (r *ResponseWriterRecorder) FirstWrite() time.Time {
+	return r.firstWrite
+}
+
 // WriteHeader wraps the underlying http.ResponseWriter and captures the response cpde.
 func (r *ResponseWriterRecorder) WriteHeader(statusCode int) {
+	if r.firstWrite.IsZero() {
+		r.firstWrite = r.now()
+	}
 	r.responseWriter.WriteHeader(statusCode)
 	r.status = statusCode
 	r.isStatusSet = true
@@ -73,7 +86,42 @@ const (
 	ContextKeyLogger    contextKey = "logger"
 )
 
-// GetRequestID returns the request ID in the context
+// contextKeyRequestState holds the requestState of the request being served by the
+// middleware.
+const contextKeyRequestState contextKey = "requestState"
+
+// requestState is the state of a request shared by the middleware and the handlers it wraps.
+type requestState struct {
+	// err is the error returned by a handler wrapped with WrapError.
+	err error
+	// requestBody is the request body as logged by logRequest.
+	requestBody interface{}
+	// minimal is set for requests left out by MiddlewareOptions.SampleRate.
+	minimal bool
+	// handlerStart is when the handler started, see MarkHandlerStart.
+	handlerStart time.Time
+	// now is the clock of the middleware logger.
+	now func() time.Time
+}
+
+func requestStateFrom(ctx context.Context) *requestState {
+	state, _ := ctx.Value(contextKeyRequestState).(*requestState)
+	return state
+}
+
+// MarkHandlerStart records that the handler of the request in ctx starts now, for the
+// "handlerMs" field of the middleware response entry. The middleware marks it right before
+// calling the next handler, middlewares it wraps can call MarkHandlerStart before calling
+// theirs so that the time they take is accounted to the "totalMs" field only. It does nothing
+// outside of the middleware.
+func MarkHandlerStart(ctx context.Context) {
+	if state := requestStateFrom(ctx); state != nil {
+		state.handlerStart = state.now()
+	}
+}
+
+// GetRequestID returns the request ID in the context, or "Unknown" if there is none. Use
+// RequestIDFromContext to tell whether the context carries a request ID.
 func GetRequestID(ctx context.Context) string {
 	requestID := ctx.Value(ContextKeyRequestID)
 
@@ -84,28 +132,181 @@ func GetRequestID(ctx context.Context) string {
 	return requestID.(string)
 }
 
+// RequestIDFromContext returns the request ID in the context and whether there is one.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(ContextKeyRequestID).(string)
+	return requestID, ok
+}
+
+// RequestIDFromRequest returns the request ID the middleware attached to the request, which
+// is also sent back in the Request-ID response header. It is empty if there is none.
+func RequestIDFromRequest(r *http.Request) string {
+	requestID, _ := RequestIDFromContext(r.Context())
+	return requestID
+}
+
+// ContextWithRequestID returns a new context carrying the request ID. Handlers building a
+// context that doesn't derive from the request's can use it to keep the request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ContextKeyRequestID, requestID)
+}
+
 // WithLogger returns a new context with the provided logger.
 func WithLogger(ctx context.Context, logger Logger) context.Context {
 	return context.WithValue(ctx, ContextKeyLogger, logger)
 }
 
 // GetLogger retrieves the current logger from the context. If no logger is
-// available, the default logger is returned.
+// available, the default logger is returned, annotated with the request ID if the context
+// carries one. Goroutines started by a handler, with errgroup.WithContext for instance, get
+// the request logger as long as they are given a context derived from the request's.
 func GetLogger(ctx context.Context) Logger {
-	logger := ctx.Value(ContextKeyLogger)
+	logger, ok := ctx.Value(ContextKeyLogger).(Logger)
 
-	if logger == nil {
-		return New(INFO, os.Stdout)
+	if !ok || logger.IsZero() {
+		defaultLogger := New(INFO, os.Stdout)
+		if requestID, ok := RequestIDFromContext(ctx); ok {
+			return defaultLogger.WithFields(map[string]interface{}{string(ContextKeyRequestID): requestID})
+		}
+		return defaultLogger
 	}
 
-	return logger.(Logger)
+	return logger
+}
+
+// DebugCtx logs the message at debug level with the logger from the context.
+func DebugCtx(ctx context.Context, msg string) {
+	GetLogger(ctx).Debugln(msg)
+}
+
+// InfoCtx logs the message at info level with the logger from the context.
+func InfoCtx(ctx context.Context, msg string) {
+	GetLogger(ctx).Infoln(msg)
+}
+
+// WarnCtx logs the message at warning level with the logger from the context.
+func WarnCtx(ctx context.Context, msg string) {
+	GetLogger(ctx).Warnln(msg)
+}
+
+// ErrorCtx logs the message at error level with the logger from the context.
+func ErrorCtx(ctx context.Context, msg string) {
+	GetLogger(ctx).Errorln(msg)
 }
 
 // MiddlewareOptions struct
 type MiddlewareOptions struct {
 	LogResponse bool
+	// DisableRequestBody stops the middleware from reading and logging the request body, the
+	// handler gets it untouched and the rest of the request is logged as usual.
+	DisableRequestBody bool
+	// TrustProxyHeaders makes the middleware log the client IP found in the X-Forwarded-For
+	// or X-Real-IP headers. Only enable it when the service sits behind a proxy that sets them.
+	TrustProxyHeaders bool
+	// RedactHeaders lists the request and response headers, matched case-insensitively,
+	// whose values are replaced by "[REDACTED]" in logs. When nil, DefaultRedactHeaders is
+	// used, set it to an empty slice to log every header as is.
+	RedactHeaders []string
+	// FlattenHeaders logs the request and response headers as "requestHeaders" and
+	// "responseHeaders" objects mapping each header to its values joined with commas,
+	// instead of the "header" fields. Only the headers listed in LoggedHeaders are included.
+	FlattenHeaders bool
+	// LoggedHeaders lists the headers, matched case-insensitively, logged when
+	// FlattenHeaders is set. When nil, DefaultLoggedHeaders is used. Redaction still applies.
+	LoggedHeaders []string
+	// SlowRequestThreshold makes responses taking longer than it logged at warning level
+	// with a "slow" field. Zero disables it.
+	SlowRequestThreshold time.Duration
+	// FieldExtractor returns extra fields to add to the request and response log entries.
+	FieldExtractor func(*http.Request) map[string]interface{}
+	// OmitRawDuration drops the "duration" field, a time.Duration in nanoseconds, from the
+	// response log and only keeps "durationMs" in float milliseconds.
+	OmitRawDuration bool
+	// RoutePattern resolves the route template matched by the request, such as
+	// "/users/{id}", to use in the "api" field instead of the raw path and keep its
+	// cardinality bounded. The raw path is used when it's nil or returns an empty string.
+	// It is called for both the request and the response log entries, routers that only
+	// resolve the route while serving the request yield the raw path on the request entry.
+	RoutePattern func(*http.Request) string
+	// RecoverPanics recovers from panics in the handler, logs them at error level with their
+	// stack and responds with a 500 status if nothing was written yet. The panic entry carries
+	// the method, path and, when it is logged, body of the request that triggered it.
+	RecoverPanics bool
+	// AccessLogKey and AccessLogValue are the field added to the request and response log
+	// entries, and only to them, so that access logs can be told apart from application
+	// logs. They default to "log_type" and "access".
+	AccessLogKey   string
+	AccessLogValue string
+	// RequestMessage and ResponseMessage are the messages of the request and response log
+	// entries. They default to "http_request" and "http_response".
+	RequestMessage  string
+	ResponseMessage string
+	// MaxBodyLogBytes is the largest request body read for logging. Bodies declaring a
+	// larger Content-Length are passed to the handler untouched, and at most MaxBodyLogBytes
+	// of the ones without a declared length are read, the handler getting the rest as it is
+	// streamed. Only the declared length of larger bodies is logged as "contentLength", and
+	// "bodyTruncated" and "bodyBytesRead" are logged for the ones without a declared length.
+	// Response bodies larger than it aren't logged either. Zero logs bodies of any length.
+	MaxBodyLogBytes int64
+	// AllowRequestLevelOverride makes the level named by the X-Log-Level request header, such
+	// as "debug", apply to the request logger, the one the handler gets from the context, for
+	// that request only. It is meant for staging environments, the header is ignored when it
+	// is disabled.
+	AllowRequestLevelOverride bool
+	// SampleRate is the proportion, between 0 and 1, of requests logged in full. The others
+	// are logged with their method, path, status and duration only. Requests are selected
+	// by their request ID, so that their request and response entries agree. Zero, like 1,
+	// logs every request in full.
+	SampleRate float64
+	// MetricNamespace, when set, makes the response log entries carry the "Latency" in
+	// milliseconds and "RequestCount" CloudWatch metrics of that namespace, see WithMetric.
+	MetricNamespace string
+	// RequestIDFormat is the format of the generated request IDs, UUIDv4 by default.
+	RequestIDFormat IDFormat
+	// LogMultipart logs the field names and the file names and sizes of multipart/form-data
+	// request bodies under a "multipart" field, file contents are never logged. It has no
+	// effect with DisableRequestBody.
+	LogMultipart bool
+	// MultipartMaxMemory bounds the size of the multipart bodies buffered to be inspected,
+	// larger ones are passed to the handler without being logged. It defaults to 32 MB.
+	MultipartMaxMemory int64
+	// LogQueryParams adds the query parameters of the request to its log entry as a
+	// "queryParams" field, a parameter repeated in the query is logged as an array.
+	LogQueryParams bool
+	// RedactQueryParams lists the query parameters, matched case-insensitively, whose values
+	// are replaced by "[REDACTED]" in the "queryParams" field. When nil,
+	// DefaultRedactQueryParams is used. The raw "uri" field isn't redacted.
+	RedactQueryParams []string
+	// FieldAllowlist, when not nil, restricts the standard fields of the request and response
+	// entries, such as "remoteAddr", "header", "userAgent", "requestBody" or "duration", to
+	// the ones it lists. The request ID, trace and error fields, and the ones returned by
+	// FieldExtractor, are always logged.
+	FieldAllowlist []string
+	// FieldDenylist lists standard fields of the request and response entries not to log,
+	// it applies after FieldAllowlist.
+	FieldDenylist []string
+	// LogResponseStatuses, when not empty, restricts the response entries to the responses
+	// with one of these statuses, such as 429 and 503 for capacity monitoring. Responses are
+	// then logged whether LogResponse is set or not.
+	LogResponseStatuses []int
+}
+
+// DefaultRedactHeaders are the headers redacted when MiddlewareOptions.RedactHeaders is nil.
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// DefaultLoggedHeaders are the headers logged when MiddlewareOptions.FlattenHeaders is set
+// and MiddlewareOptions.LoggedHeaders is nil.
+var DefaultLoggedHeaders = []string{
+	"Accept", "Content-Encoding", "Content-Length", "Content-Type", "Location", "Referer",
+	"Request-ID", "User-Agent", "X-Forwarded-For", "X-Real-IP",
 }
 
+// DefaultRedactQueryParams are the query parameters redacted when
+// MiddlewareOptions.RedactQueryParams is nil.
+var DefaultRedactQueryParams = []string{"token", "access_token", "api_key", "apikey", "password", "secret"}
+
+const redacted = "[REDACTED]"
+
 // NewMiddleware creates a new middleware for logging
 func NewMiddleware(next http.Handler, logger Logger) http.Handler {
 	return NewMiddlewareWithOptions(next, logger, MiddlewareOptions{
@@ -115,60 +316,275 @@ func NewMiddleware(next http.Handler, logger Logger) http.Handler {
 
 // NewMiddlewareWithOptions creates a new middleware for logging
 func NewMiddlewareWithOptions(next http.Handler, logger Logger, options MiddlewareOptions) http.Handler {
-	if &logger == nil {
+	if logger.IsZero() {
 		logger = New(INFO, os.Stdout)
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+		start := logger.now()
 
-		// attach request ID to the request
-		requestID := uuid.New().String()
-		ctx := context.WithValue(r.Context(), ContextKeyRequestID, requestID)
-		r = r.WithContext(ctx)
+		// attach request ID to the request, reusing the one of an outer middleware if nested
+		requestID, ok := RequestIDFromContext(r.Context())
+		if !ok {
+			requestID = newRequestID(options.RequestIDFormat)
+			r = r.WithContext(ContextWithRequestID(r.Context(), requestID))
+		}
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyRequestState, &requestState{
+			minimal: !sampleRequest(requestID, options.SampleRate),
+			now:     logger.now,
+		}))
 
-		// attach the request ID to the logger
+		// attach the request ID, the active trace and the request context to the logger
 		loggerWithRequestID := logger.WithFields(map[string]interface{}{string(ContextKeyRequestID): requestID})
+		loggerWithRequestID = WithTraceContext(r.Context(), loggerWithRequestID).WithContext(r.Context())
+		if options.AllowRequestLevelOverride {
+			loggerWithRequestID = overrideLevel(loggerWithRequestID, r.Header.Get(logLevelHeader))
+		}
 		r = r.WithContext(WithLogger(r.Context(), loggerWithRequestID))
 
-		logRequest(loggerWithRequestID, r)
+		accessLogKey, accessLogValue := options.AccessLogKey, options.AccessLogValue
+		if accessLogKey == "" {
+			accessLogKey = "log_type"
+		}
+		if accessLogValue == "" {
+			accessLogValue = "access"
+		}
+		accessLogger := loggerWithRequestID.WithFields(map[string]interface{}{accessLogKey: accessLogValue})
+		if options.FieldExtractor != nil {
+			accessLogger = accessLogger.WithFields(options.FieldExtractor(r))
+		}
+
+		logRequest(accessLogger, r, options)
 
 		responseWriterRecorder := NewResponseWriterRecorder(w)
-		if options.LogResponse {
-			defer logResponse(loggerWithRequestID, start, r, responseWriterRecorder)
+		responseWriterRecorder.now = logger.now
+		if options.LogResponse || len(options.LogResponseStatuses) > 0 {
+			defer logResponse(accessLogger, start, r, responseWriterRecorder, options)
 		}
 
-		responseWriterRecorder.Header().Add("Request-ID", requestID)
+		if options.RecoverPanics {
+			defer recoverPanic(loggerWithRequestID, r, responseWriterRecorder)
+		}
+
+		responseWriterRecorder.Header().Set("Request-ID", requestID)
+		MarkHandlerStart(r.Context())
 		next.ServeHTTP(responseWriterRecorder, r)
 	})
 }
 
-func logRequest(logger Logger, r *http.Request) {
+// logLevelHeader names the level of the request logger, see
+// MiddlewareOptions.AllowRequestLevelOverride.
+const logLevelHeader = "X-Log-Level"
+
+// overrideLevel returns an independent copy of logger at the given level, or logger itself if
+// the level is empty or unknown.
+func overrideLevel(logger Logger, level string) Logger {
+	var l Level
+	if level == "" || l.Set(level) != nil {
+		return logger
+	}
+
+	logger = logger.Clone()
+	logger.SetLevel(l)
+	return logger
+}
+
+// recoverPanic must be deferred, it logs the panic and turns it into a 500 response. It runs
+// before the deferred logResponse so that the status is logged.
+func recoverPanic(logger Logger, r *http.Request, w *ResponseWriterRecorder) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered == http.ErrAbortHandler {
+		// the handler deliberately aborted the response, let net/http handle it
+		panic(recovered)
+	}
+
+	// the handler consumed the body, the copy read by logRequest is logged instead
+	fields := map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}
+	if state := requestStateFrom(r.Context()); state != nil && state.requestBody != nil {
+		fields["requestBody"] = state.requestBody
+	}
+	logger.WithFields(fields).WithPanic(recovered).Errorln("panic recovered")
+	if !w.isStatusSet {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func logRequest(logger Logger, r *http.Request, options MiddlewareOptions) {
+	state := requestStateFrom(r.Context())
+	if state != nil && state.minimal {
+		fields := getFields()
+		defer putFields(fields)
+
+		fields["method"] = r.Method
+		fields["path"] = r.URL.Path
+		fields["api"] = api(r, options.RoutePattern)
+		filterFields(fields, options)
+		logger.WithFields(fields).Debugln(messageOrDefault(options.RequestMessage, "http_request"))
+		return
+	}
+
 	var requestBody interface{}
-	if r.Body != http.NoBody {
-		buf, err := ioutil.ReadAll(r.Body)
-		if err != nil {
+	switch {
+	case options.DisableRequestBody:
+		// the body is left untouched for the handler
+	case options.LogMultipart && isMultipart(r):
+		logger = logMultipart(logger, r, options.MultipartMaxMemory)
+	case skipRequestBody(r, options.MaxBodyLogBytes):
+		// binary and oversized bodies are left for the handler to stream
+		logger = logger.WithFields(skippedBodyFields(r.ContentLength))
+	case r.Body != nil && r.Body != http.NoBody:
+		// the handler gets the body as it was sent, only the logged copy is decoded, and
+		// bodies without a declared length are only read up to MaxBodyLogBytes
+		var buf []byte
+		var truncated bool
+		var err error
+		buf, r.Body, truncated, err = readLimitedBody(r.Body, options.MaxBodyLogBytes)
+		switch {
+		case err != nil:
 			logger = logger.WithFields(map[string]interface{}{"bodyError": err})
-		} else {
-			r.Body = ioutil.NopCloser(bytes.NewBuffer(buf))
-			bufCopy := bytes.NewBuffer(buf)
-			if err := json.Unmarshal(bufCopy.Bytes(), &requestBody); err != nil {
-				requestBody = bufCopy.String()
-				logger = logger.WithFields(map[string]interface{}{"bodyError": err})
-			}
+		case truncated:
+			logger = logger.WithFields(truncatedBodyFields(r.ContentLength, len(buf)))
+		default:
+			requestBody, logger = parseBody(logger, r.Header, buf, options.MaxBodyLogBytes)
 		}
 	}
 
 	m := convertRequestBody(requestBody)
+	if state != nil {
+		state.requestBody = m
+	}
+
+	if options.LogQueryParams {
+		logger = logger.WithFields(map[string]interface{}{
+			"queryParams": queryParams(r, options.RedactQueryParams),
+		})
+	}
+
+	fields := getFields()
+	defer putFields(fields)
+
+	fields["remoteAddr"] = r.RemoteAddr
+	fields["clientIP"] = clientIP(r, options.TrustProxyHeaders)
+	fields["protocol"] = r.Proto
+	fields["method"] = r.Method
+	if options.FlattenHeaders {
+		fields["requestHeaders"] = flattenHeaders(r.Header, options)
+	} else {
+		fields["header"] = redactHeaders(r.Header, options.RedactHeaders)
+	}
+	fields["uri"] = r.RequestURI
+	fields["path"] = r.URL.Path
+	fields["api"] = api(r, options.RoutePattern)
+	fields["userAgent"] = r.UserAgent()
+	fields["contentType"] = r.Header.Get("Content-Type")
+	fields["requestBody"] = m
+	filterFields(fields, options)
+	logger.WithFields(fields).Debugln(messageOrDefault(options.RequestMessage, "http_request"))
+}
+
+// skipRequestBody reports whether the request body shouldn't be read for logging, because its
+// content type isn't textual or its declared length exceeds maxBytes.
+func skipRequestBody(r *http.Request, maxBytes int64) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	if maxBytes > 0 && r.ContentLength > maxBytes {
+		return true
+	}
 
-	logger.WithFields(map[string]interface{}{
-		"remoteAddr":  r.RemoteAddr,
-		"protocol":    r.Proto,
-		"method":      r.Method,
-		"header":      r.Header,
-		"uri":         r.RequestURI,
-		"userAgent":   r.UserAgent(),
-		"requestBody": m,
-	}).Debugln("")
+	return bodyKindOf(r.Header.Get("Content-Type")) == bodyKindBinary
+}
+
+// clientIP returns the IP of the client that originated the request. When trustProxyHeaders
+// is set, the left-most entry of X-Forwarded-For, or else X-Real-IP, is preferred over the
+// connection's remote address.
+func clientIP(r *http.Request, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		for _, forwarded := range strings.Split(r.Header.Get("X-Forwarded-For"), ",") {
+			if ip := strings.TrimSpace(forwarded); ip != "" {
+				return ip
+			}
+		}
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// redactHeaders returns a copy of header with the values of the given names redacted, the
+// original header is left untouched.
+func redactHeaders(header http.Header, names []string) http.Header {
+	if names == nil {
+		names = DefaultRedactHeaders
+	}
+
+	logged := header.Clone()
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if values, ok := logged[name]; ok {
+			redactedValues := make([]string, len(values))
+			for i := range redactedValues {
+				redactedValues[i] = redacted
+			}
+			logged[name] = redactedValues
+		}
+	}
+
+	return logged
+}
+
+// queryParams returns the query parameters of the request with the values of the given names
+// redacted.
+func queryParams(r *http.Request, names []string) map[string]interface{} {
+	if names == nil {
+		names = DefaultRedactQueryParams
+	}
+
+	query := r.URL.Query()
+	for param, values := range query {
+		for _, name := range names {
+			if strings.EqualFold(param, name) {
+				redactedValues := make([]string, len(values))
+				for i := range redactedValues {
+					redactedValues[i] = redacted
+				}
+				query[param] = redactedValues
+				break
+			}
+		}
+	}
+
+	return flattenValues(query)
+}
+
+// flattenHeaders returns the redacted values of the logged headers, joined with commas.
+func flattenHeaders(header http.Header, options MiddlewareOptions) map[string]string {
+	names := options.LoggedHeaders
+	if names == nil {
+		names = DefaultLoggedHeaders
+	}
+
+	header = redactHeaders(header, options.RedactHeaders)
+	flattened := make(map[string]string, len(names))
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if values, ok := header[name]; ok {
+			flattened[name] = strings.Join(values, ",")
+		}
+	}
+
+	return flattened
 }
 
 func convertRequestBody(requestBody interface{}) interface{} {
@@ -187,19 +603,170 @@ func convertRequestBody(requestBody interface{}) interface{} {
 	}
 }
 
-func logResponse(logger Logger, start time.Time, r *http.Request, w *ResponseWriterRecorder) {
+func logResponse(logger Logger, start time.Time, r *http.Request, w *ResponseWriterRecorder, options MiddlewareOptions) {
+	if len(options.LogResponseStatuses) > 0 && !containsInt(options.LogResponseStatuses, w.Status()) {
+		return
+	}
+
+	state := requestStateFrom(r.Context())
+	minimal := state != nil && state.minimal
+
 	var responseBody interface{}
-	if w.Body() != nil {
-		if err := json.Unmarshal(w.Body(), &responseBody); err != nil {
-			responseBody = string(w.Body())
-			logger = logger.WithFields(map[string]interface{}{"bodyError": err})
+	switch body := w.Body(); {
+	case body == nil, minimal:
+	case options.MaxBodyLogBytes > 0 && int64(len(body)) > options.MaxBodyLogBytes:
+		logger = logger.WithFields(map[string]interface{}{"contentLength": len(body)})
+	default:
+		// only JSON bodies are parsed, text ones are logged as is and binary ones skipped
+		responseBody, logger = parseBody(logger, w.Header(), body, options.MaxBodyLogBytes)
+	}
+
+	duration := logger.since(start)
+	fields := getFields()
+	defer putFields(fields)
+
+	if !options.OmitRawDuration {
+		fields["duration"] = duration
+	}
+	fields["durationMs"] = durationMs(duration)
+	fields["totalMs"] = durationMs(duration)
+	if state != nil && !state.handlerStart.IsZero() {
+		fields["handlerMs"] = durationMs(logger.since(state.handlerStart))
+	}
+	fields["ttfbMs"] = durationMs(duration)
+	if firstWrite := w.FirstWrite(); !firstWrite.IsZero() {
+		fields["ttfbMs"] = durationMs(firstWrite.Sub(start))
+	}
+	if !minimal {
+		if options.FlattenHeaders {
+			fields["responseHeaders"] = flattenHeaders(w.Header(), options)
+		} else {
+			fields["header"] = redactHeaders(w.Header(), options.RedactHeaders)
+		}
+		fields["responseBody"] = responseBody
+		fields["contentType"] = w.Header().Get("Content-Type")
+	}
+	fields["status"] = w.Status()
+	fields["statusClass"] = statusClass(w.Status())
+	fields["method"] = r.Method
+	fields["path"] = r.URL.Path
+	fields["api"] = api(r, options.RoutePattern)
+	filterFields(fields, options)
+	if state != nil && state.err != nil {
+		fields[ErrorKey] = state.err
+	}
+	logger = logger.WithFields(fields)
+	if options.MetricNamespace != "" {
+		logger = logger.
+			WithMetric(options.MetricNamespace, "Latency", durationMs(duration), "Milliseconds").
+			WithMetric(options.MetricNamespace, "RequestCount", 1, "Count")
+	}
+
+	level := DEBUG
+	if options.SlowRequestThreshold > 0 && duration > options.SlowRequestThreshold {
+		logger = logger.WithFields(map[string]interface{}{"slow": true})
+		level = WARN
+	}
+	// the logged status is meaningless for a request aborted before the handler responded
+	switch r.Context().Err() {
+	case context.Canceled:
+		logger = logger.WithFields(map[string]interface{}{"clientCanceled": true})
+		level = WARN
+	case context.DeadlineExceeded:
+		logger = logger.WithFields(map[string]interface{}{"timedOut": true})
+		level = WARN
+	}
+	logger.Logln(level, messageOrDefault(options.ResponseMessage, "http_response"))
+}
+
+// sampleRequest reports whether the request is logged in full according to the sample rate,
+// the same request ID always gets the same answer.
+func sampleRequest(requestID string, rate float64) bool {
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(requestID))
+	return float64(h.Sum32()) < rate*float64(math.MaxUint32)
+}
+
+// fieldsPool holds the maps of the request and response log entries, WithFields doesn't keep
+// the maps it is given.
+var fieldsPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string]interface{}, 16)
+	},
+}
+
+func getFields() map[string]interface{} {
+	return fieldsPool.Get().(map[string]interface{})
+}
+
+func putFields(fields map[string]interface{}) {
+	for k := range fields {
+		delete(fields, k)
+	}
+	fieldsPool.Put(fields)
+}
+
+// filterFields removes the fields left out by MiddlewareOptions.FieldAllowlist and
+// MiddlewareOptions.FieldDenylist.
+func filterFields(fields map[string]interface{}, options MiddlewareOptions) {
+	if options.FieldAllowlist != nil {
+		for k := range fields {
+			if !containsString(options.FieldAllowlist, k) {
+				delete(fields, k)
+			}
+		}
+	}
+	for _, k := range options.FieldDenylist {
+		delete(fields, k)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
 		}
 	}
-	logger.WithFields(map[string]interface{}{
-		"duration":     time.Since(start),
-		"header":       w.Header(),
-		"responseBody": responseBody,
-		"status":       w.Status(),
-		"api":          fmt.Sprintf("%s_%s", r.Method, r.URL.Path),
-	}).Debugln("")
+	return false
+}
+
+func messageOrDefault(msg, defaultMsg string) string {
+	if msg == "" {
+		return defaultMsg
+	}
+	return msg
+}
+
+// api identifies the endpoint a request was sent to, as METHOD_route.
+func api(r *http.Request, routePattern func(*http.Request) string) string {
+	route := r.URL.Path
+	if routePattern != nil {
+		if pattern := routePattern(r); pattern != "" {
+			route = pattern
+		}
+	}
+
+	return fmt.Sprintf("%s_%s", r.Method, route)
+}
+
+// statusClass returns the class of an HTTP status, such as "2xx" for 204.
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
 }
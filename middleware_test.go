@@ -0,0 +1,673 @@
+package golog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestMiddleware wraps next in the middleware logging to the returned observer.
+func newTestMiddleware(next http.Handler, options MiddlewareOptions) (http.Handler, *Observer) {
+	logger, observer := NewObserver(DEBUG)
+	return NewMiddlewareWithOptions(next, logger, options), observer
+}
+
+// entriesWithMessage returns the entries recorded by observer with the given message.
+func entriesWithMessage(observer *Observer, msg string) []Entry {
+	var entries []Entry
+	for _, e := range observer.Entries() {
+		if e.Message == msg {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// responseEntry returns the single response entry recorded by observer.
+func responseEntry(t *testing.T, observer *Observer) Entry {
+	t.Helper()
+
+	entries := entriesWithMessage(observer, "http_response")
+	if len(entries) != 1 {
+		t.Fatalf("got %d response entries, want 1", len(entries))
+	}
+	return entries[0]
+}
+
+// requestEntry returns the single request entry recorded by observer.
+func requestEntry(t *testing.T, observer *Observer) Entry {
+	t.Helper()
+
+	entries := entriesWithMessage(observer, "http_request")
+	if len(entries) != 1 {
+		t.Fatalf("got %d request entries, want 1", len(entries))
+	}
+	return entries[0]
+}
+
+func TestMiddlewareZeroLogger(t *testing.T) {
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetLogger(r.Context()).Debugln("handling")
+		w.WriteHeader(http.StatusNoContent)
+	}), Logger{})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestMiddlewareSlowRequestThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		threshold time.Duration
+		wantLevel string
+		wantSlow  bool
+	}{
+		{name: "slow", elapsed: 20 * time.Millisecond, threshold: 5 * time.Millisecond, wantLevel: "warning", wantSlow: true},
+		{name: "at the threshold", elapsed: 5 * time.Millisecond, threshold: 5 * time.Millisecond, wantLevel: "debug"},
+		{name: "fast", elapsed: time.Millisecond, threshold: time.Second, wantLevel: "debug"},
+		{name: "disabled", elapsed: time.Hour, wantLevel: "debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newTestClock()
+			logger, buf := newTestLogger(t, DEBUG, WithClock(clock))
+			handler := NewMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				clock.advance(tt.elapsed)
+			}), logger, MiddlewareOptions{LogResponse: true, SlowRequestThreshold: tt.threshold})
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := decodeEntries(t, buf)
+			response := entries[len(entries)-1]
+			if response["severity"] != tt.wantLevel {
+				t.Errorf("severity = %v, want %v", response["severity"], tt.wantLevel)
+			}
+			if _, slow := response["slow"]; slow != tt.wantSlow {
+				t.Errorf("slow field present = %v, want %v", slow, tt.wantSlow)
+			}
+		})
+	}
+}
+
+func TestMiddlewareOutputIsNDJSON(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG, WithOmitEmptyMessage())
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetLogger(r.Context()).Infoln("")
+		w.Write([]byte(`{"ok":true}`))
+	}), logger)
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`)))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("got %d lines, want 9: %q", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line %d isn't a JSON object: %q: %v", i, line, err)
+		}
+		if msg, ok := entry["message"]; ok && msg == "" {
+			t.Errorf("line %d has an empty message: %q", i, line)
+		}
+	}
+}
+
+func BenchmarkMiddleware(b *testing.B) {
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}), New(DEBUG, io.Discard))
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestMiddlewareMaxBodyLogBytesChunked(t *testing.T) {
+	sent := strings.Repeat("a", 1000)
+	body := &countingReader{r: strings.NewReader(sent)}
+
+	var readBeforeHandler int
+	var received string
+	handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readBeforeHandler = body.n
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+	}), MiddlewareOptions{MaxBodyLogBytes: 100})
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "text/plain")
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if readBeforeHandler > 101 {
+		t.Errorf("read %d bytes before the handler, want at most 101", readBeforeHandler)
+	}
+	if received != sent {
+		t.Errorf("handler got %d bytes, want %d", len(received), len(sent))
+	}
+	entry := requestEntry(t, observer)
+	if entry.Fields["requestBody"] != nil {
+		t.Errorf("requestBody = %v, want none", entry.Fields["requestBody"])
+	}
+	if _, ok := entry.Fields["contentLength"]; ok {
+		t.Errorf("contentLength = %v, want none without a declared length", entry.Fields["contentLength"])
+	}
+	if entry.Fields["bodyTruncated"] != true || entry.Fields["bodyBytesRead"] != 101 {
+		t.Errorf("bodyTruncated, bodyBytesRead = %v, %v, want true, 101", entry.Fields["bodyTruncated"], entry.Fields["bodyBytesRead"])
+	}
+}
+
+func TestMiddlewareSkipsBinaryAndOversizedBodies(t *testing.T) {
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		wantBody      interface{}
+		wantLength    bool
+		maxBodyLength int64
+	}{
+		{name: "json", contentType: "application/json", body: `{"a":"b"}`, wantBody: map[string]interface{}{"a": "b"}},
+		{name: "binary", contentType: "application/octet-stream", body: "\x00\x01", wantLength: true},
+		{name: "image", contentType: "image/png", body: "\x89PNG", wantLength: true},
+		{name: "declared length over the limit", contentType: "text/plain", body: "0123456789", maxBodyLength: 5, wantLength: true},
+		{name: "under the limit", contentType: "text/plain", body: "0123", maxBodyLength: 5, wantBody: "0123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+			}), MiddlewareOptions{MaxBodyLogBytes: tt.maxBodyLength})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if received != tt.body {
+				t.Errorf("handler got %q, want %q", received, tt.body)
+			}
+			entry := requestEntry(t, observer)
+			if got := entry.Fields["requestBody"]; !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("requestBody = %#v, want %#v", got, tt.wantBody)
+			}
+			if _, ok := entry.Fields["contentLength"]; ok != tt.wantLength {
+				t.Errorf("contentLength present = %v, want %v", ok, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestMiddlewareStatusClass(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{199, "1xx"},
+		{200, "2xx"},
+		{299, "2xx"},
+		{300, "3xx"},
+		{399, "3xx"},
+		{400, "4xx"},
+		{500, "5xx"},
+		{599, "5xx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}), MiddlewareOptions{LogResponse: true})
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entry := responseEntry(t, observer)
+			if got := entry.Fields["statusClass"]; got != tt.want {
+				t.Errorf("statusClass = %v, want %q", got, tt.want)
+			}
+			if got := entry.Fields["status"]; got != tt.status {
+				t.Errorf("status = %v, want %d", got, tt.status)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRequestBody(t *testing.T) {
+	const sent = `{"name":"n"}`
+	tests := []struct {
+		name     string
+		options  MiddlewareOptions
+		wantBody interface{}
+	}{
+		{name: "zero options", wantBody: map[string]interface{}{"name": "n"}},
+		{name: "disabled", options: MiddlewareOptions{DisableRequestBody: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+			}), tt.options)
+
+			req := httptest.NewRequest(http.MethodPost, "/users?id=1", strings.NewReader(sent))
+			req.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if received != sent {
+				t.Errorf("handler got %q, want %q", received, sent)
+			}
+			entry := requestEntry(t, observer)
+			if got := entry.Fields["requestBody"]; !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("requestBody = %#v, want %#v", got, tt.wantBody)
+			}
+			if entry.Fields["method"] != http.MethodPost || entry.Fields["uri"] != "/users?id=1" {
+				t.Errorf("request metadata missing: %v", entry.Fields)
+			}
+			if _, ok := entry.Fields["header"]; !ok {
+				t.Errorf("header missing")
+			}
+		})
+	}
+}
+
+func TestMiddlewareTimeToFirstByte(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        func(clock *testClock, w http.ResponseWriter)
+		wantTTFBMs     float64
+		wantDurationMs float64
+	}{
+		{
+			name: "write",
+			handler: func(clock *testClock, w http.ResponseWriter) {
+				clock.advance(20 * time.Millisecond)
+				w.Write([]byte("first"))
+				clock.advance(30 * time.Millisecond)
+				w.Write([]byte(" last"))
+			},
+			wantTTFBMs:     20,
+			wantDurationMs: 50,
+		},
+		{
+			name: "header",
+			handler: func(clock *testClock, w http.ResponseWriter) {
+				clock.advance(5 * time.Millisecond)
+				w.WriteHeader(http.StatusNoContent)
+				clock.advance(5 * time.Millisecond)
+			},
+			wantTTFBMs:     5,
+			wantDurationMs: 10,
+		},
+		{
+			name: "no write",
+			handler: func(clock *testClock, w http.ResponseWriter) {
+				clock.advance(5 * time.Millisecond)
+			},
+			wantTTFBMs:     5,
+			wantDurationMs: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newTestClock()
+			logger, buf := newTestLogger(t, DEBUG, WithClock(clock))
+			handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(clock, w)
+			}), logger)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := decodeEntries(t, buf)
+			response := entries[len(entries)-1]
+			if response["ttfbMs"] != tt.wantTTFBMs || response["durationMs"] != tt.wantDurationMs {
+				t.Errorf("ttfbMs, durationMs = %v, %v, want %v, %v", response["ttfbMs"], response["durationMs"], tt.wantTTFBMs, tt.wantDurationMs)
+			}
+		})
+	}
+}
+
+func TestMiddlewareLargeIntegerIDs(t *testing.T) {
+	const id = "1234567890123456789"
+	logger, buf := newTestLogger(t, DEBUG)
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":` + id + `}`))
+	}), logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":`+id+`}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if !strings.Contains(buf.String(), `"requestBody":{"id":`+id+`}`) {
+		t.Errorf("request entry %q, want the id %s unchanged", buf.String(), id)
+	}
+	if !strings.Contains(buf.String(), `"responseBody":{"id":`+id+`}`) {
+		t.Errorf("response entry %q, want the id %s unchanged", buf.String(), id)
+	}
+}
+
+func TestMiddlewareWorkerCorrelation(t *testing.T) {
+	const requests, workers = 5, 3
+	handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(ctx context.Context, id string) {
+				defer wg.Done()
+				GetLogger(ctx).ForWorker(id).Infoln("working")
+			}(r.Context(), strconv.Itoa(i))
+		}
+		wg.Wait()
+	}), MiddlewareOptions{})
+
+	var mu sync.Mutex
+	requestIDs := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			mu.Lock()
+			requestIDs[w.Header().Get("Request-ID")] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	workersByRequest := make(map[interface{}]map[interface{}]bool)
+	for _, e := range entriesWithMessage(observer, "working") {
+		requestID := e.Fields[string(ContextKeyRequestID)]
+		if !requestIDs[requestID.(string)] {
+			t.Fatalf("worker entry with requestId %v, want one of the responses", requestID)
+		}
+		if workersByRequest[requestID] == nil {
+			workersByRequest[requestID] = make(map[interface{}]bool)
+		}
+		workersByRequest[requestID][e.Fields[WorkerKey]] = true
+	}
+	if len(workersByRequest) != requests {
+		t.Fatalf("got worker entries for %d requests, want %d", len(workersByRequest), requests)
+	}
+	for requestID, ids := range workersByRequest {
+		if len(ids) != workers {
+			t.Errorf("request %v: got workers %v, want %d distinct ones", requestID, ids, workers)
+		}
+	}
+}
+
+func TestMiddlewareNested(t *testing.T) {
+	logger, observer := NewObserver(DEBUG)
+	var handlerRequestID string
+	inner := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerRequestID, _ = RequestIDFromContext(r.Context())
+		GetLogger(r.Context()).Infoln("handling")
+	}), logger)
+	handler := NewMiddleware(inner, logger)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	headers := w.Header().Values("Request-ID")
+	if len(headers) != 1 {
+		t.Fatalf("Request-ID headers = %q, want a single one", headers)
+	}
+	if handlerRequestID != headers[0] {
+		t.Errorf("handler request ID = %q, want the header %q", handlerRequestID, headers[0])
+	}
+	entries := observer.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want a request and a response entry per middleware and the handler one", len(entries))
+	}
+	for _, e := range entries {
+		if e.Fields[string(ContextKeyRequestID)] != headers[0] {
+			t.Errorf("%s entry with requestId %v, want %s", e.Message, e.Fields[string(ContextKeyRequestID)], headers[0])
+		}
+	}
+}
+
+// testClock is a Clock only moving forward when advanced.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// newTestClock returns a testClock starting at a fixed time.
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestMarkHandlerStart(t *testing.T) {
+	clock := newTestClock()
+	logger, buf := newTestLogger(t, DEBUG, WithClock(clock))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.advance(10 * time.Millisecond)
+	})
+	// authenticate takes 30ms before calling the handler
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clock.advance(30 * time.Millisecond)
+			MarkHandlerStart(r.Context())
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	tests := []struct {
+		name          string
+		handler       http.Handler
+		wantTotalMs   float64
+		wantHandlerMs float64
+	}{
+		{name: "handler only", handler: handler, wantTotalMs: 10, wantHandlerMs: 10},
+		{name: "wrapping middleware", handler: authenticate(handler), wantTotalMs: 40, wantHandlerMs: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			NewMiddleware(tt.handler, logger).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := decodeEntries(t, buf)
+			response := entries[len(entries)-1]
+			if response["totalMs"] != tt.wantTotalMs || response["handlerMs"] != tt.wantHandlerMs {
+				t.Errorf("totalMs, handlerMs = %v, %v, want %v, %v", response["totalMs"], response["handlerMs"], tt.wantTotalMs, tt.wantHandlerMs)
+			}
+		})
+	}
+}
+
+func TestMiddlewareFieldFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  MiddlewareOptions
+		want     []string
+		wantNone []string
+	}{
+		{
+			name:     "allowlist",
+			options:  MiddlewareOptions{FieldAllowlist: []string{"method", "status"}},
+			want:     []string{"method", string(ContextKeyRequestID), "log_type"},
+			wantNone: []string{"path", "api", "userAgent", "header", "durationMs"},
+		},
+		{
+			name:     "denylist",
+			options:  MiddlewareOptions{FieldDenylist: []string{"path", "api", "header"}},
+			want:     []string{"method", string(ContextKeyRequestID)},
+			wantNone: []string{"path", "api", "header"},
+		},
+		{
+			name:     "allowlist then denylist",
+			options:  MiddlewareOptions{FieldAllowlist: []string{"method", "path"}, FieldDenylist: []string{"path"}},
+			want:     []string{"method"},
+			wantNone: []string{"path", "api"},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, sampled := range []bool{true, false} {
+			t.Run(tt.name+"/sampled "+strconv.FormatBool(sampled), func(t *testing.T) {
+				options := tt.options
+				options.LogResponse = true
+				if !sampled {
+					// logs the request ID below with its method, path and api only
+					options.SampleRate = 1e-9
+					if sampleRequest("req-1", options.SampleRate) {
+						t.Fatalf("req-1 is sampled at rate %v", options.SampleRate)
+					}
+				}
+				handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), options)
+
+				req := httptest.NewRequest(http.MethodGet, "/users", nil)
+				handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ContextWithRequestID(req.Context(), "req-1")))
+
+				entries := []Entry{requestEntry(t, observer), responseEntry(t, observer)}
+				for _, e := range entries {
+					for _, key := range tt.want {
+						if _, ok := e.Fields[key]; !ok {
+							t.Errorf("%s entry: %s missing in %v", e.Message, key, e.Fields)
+						}
+					}
+					for _, key := range tt.wantNone {
+						if _, ok := e.Fields[key]; ok {
+							t.Errorf("%s entry: %s present in %v", e.Message, key, e.Fields)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMiddlewareLogResponseStatuses(t *testing.T) {
+	tests := []struct {
+		name        string
+		logResponse bool
+		status      int
+		wantLogged  bool
+	}{
+		{name: "listed", status: http.StatusTooManyRequests, wantLogged: true},
+		{name: "other listed", status: http.StatusServiceUnavailable, wantLogged: true},
+		{name: "not listed", status: http.StatusOK},
+		{name: "not listed with LogResponse", logResponse: true, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}), MiddlewareOptions{
+				LogResponse:         tt.logResponse,
+				LogResponseStatuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			})
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := entriesWithMessage(observer, "http_response")
+			if logged := len(entries) == 1; logged != tt.wantLogged || len(entries) > 1 {
+				t.Fatalf("got %d response entries for status %d, want logged %v", len(entries), tt.status, tt.wantLogged)
+			}
+			if tt.wantLogged && entries[0].Fields["status"] != tt.status {
+				t.Errorf("status = %v, want %d", entries[0].Fields["status"], tt.status)
+			}
+			requestEntry(t, observer)
+		})
+	}
+}
+
+func TestRequestIDFromRequest(t *testing.T) {
+	var handlerRequestID string
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerRequestID = RequestIDFromRequest(r)
+	}), NewNop())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if handlerRequestID == "" || handlerRequestID != w.Header().Get("Request-ID") {
+		t.Errorf("RequestIDFromRequest() = %q, want the Request-ID header %q", handlerRequestID, w.Header().Get("Request-ID"))
+	}
+	if got := RequestIDFromRequest(httptest.NewRequest(http.MethodGet, "/", nil)); got != "" {
+		t.Errorf("RequestIDFromRequest() = %q outside of the middleware, want empty", got)
+	}
+}
+
+func TestMiddlewareMultipartOverMaxMemory(t *testing.T) {
+	body := "--b\r\nContent-Disposition: form-data; name=\"f\"\r\n\r\n" + strings.Repeat("a", 100) + "\r\n--b--\r\n"
+	var received string
+	handler, observer := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+	}), MiddlewareOptions{LogMultipart: true, MultipartMaxMemory: 50})
+
+	for _, contentLength := range []int64{int64(len(body)), -1} {
+		observer.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=b")
+		req.ContentLength = contentLength
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if received != body {
+			t.Errorf("content length %d: handler got %q, want the whole body", contentLength, received)
+		}
+		entry := requestEntry(t, observer)
+		if entry.Fields["bodyTruncated"] != true || entry.Fields["bodyBytesRead"] != 51 {
+			t.Errorf("content length %d: bodyTruncated, bodyBytesRead = %v, %v, want true, 51", contentLength, entry.Fields["bodyTruncated"], entry.Fields["bodyBytesRead"])
+		}
+		if length, ok := entry.Fields["contentLength"]; ok != (contentLength >= 0) || ok && length != contentLength {
+			t.Errorf("content length %d: contentLength = %v, want it only when declared", contentLength, length)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// defaultMultipartMaxMemory is the default of MiddlewareOptions.MultipartMaxMemory, the same
+// as net/http's for ParseMultipartForm.
+const defaultMultipartMaxMemory = 32 << 20
+
+type multipartFile struct {
+	Field    string `json:"field"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+type multipartSummary struct {
+	Fields []string        `json:"fields"`
+	Files  []multipartFile `json:"files"`
+}
+
+func isMultipart(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// logMultipart returns the logger with the summary of the multipart body of the request, which
+// is restored for the handler. Bodies larger than maxMemory aren't inspected.
+func logMultipart(logger Logger, r *http.Request, maxMemory int64) Logger {
+	if maxMemory <= 0 {
+		maxMemory = defaultMultipartMaxMemory
+	}
+
+	buf, err := ioutil.ReadAll(io.LimitReader(r.Body, maxMemory+1))
+	if err != nil {
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(buf), r.Body))
+		return logger.WithFields(map[string]interface{}{"bodyError": err})
+	}
+	if int64(len(buf)) > maxMemory {
+		// the rest of the body is streamed to the handler after what was read
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+		return logger.WithFields(truncatedBodyFields(r.ContentLength, len(buf)))
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(buf))
+
+	summary, err := summarizeMultipart(r.Header.Get("Content-Type"), buf)
+	if err != nil {
+		logger = logger.WithFields(map[string]interface{}{"bodyError": err})
+	}
+
+	return logger.WithFields(map[string]interface{}{"multipart": summary})
+}
+
+// summarizeMultipart lists the fields and files of a multipart body, the file contents are
+// only counted.
+func summarizeMultipart(contentType string, buf []byte) (multipartSummary, error) {
+	summary := multipartSummary{
+		Fields: []string{},
+		Files:  []multipartFile{},
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return summary, err
+	}
+	boundary, ok := params["boundary"]
+	if !ok {
+		return summary, fmt.Errorf("golog: multipart body without boundary")
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(buf), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return summary, nil
+		}
+		if err != nil {
+			return summary, err
+		}
+
+		size, err := io.Copy(ioutil.Discard, part)
+		part.Close()
+		if err != nil {
+			return summary, err
+		}
+
+		if part.FileName() == "" {
+			summary.Fields = append(summary.Fields, part.FormName())
+		} else {
+			summary.Files = append(summary.Files, multipartFile{
+				Field:    part.FormName(),
+				Filename: part.FileName(),
+				Size:     size,
+			})
+		}
+	}
+}
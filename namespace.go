@@ -0,0 +1,72 @@
+package golog
+
+// WithNamespace returns a new logger nesting the fields added to it, through WithFields and
+// the methods built on it, inside an object named name, as in {"name": {"key": "value"}}.
+// Namespaces stack, WithNamespace("a").WithNamespace("b") nests fields under a then b. The
+// namespace object replaces a field previously added with the same name, and fields of the
+// parent logger are left as they are. An empty name returns the logger unchanged.
+func (l Logger) WithNamespace(name string) Logger {
+	if name == "" || l.core != nil && l.core.nop {
+		return l
+	}
+
+	namespace := make([]string, len(l.namespace), len(l.namespace)+1)
+	copy(namespace, l.namespace)
+	l.namespace = append(namespace, name)
+
+	return l
+}
+
+// nestFields returns fields nested inside the logger's namespace, merged with the fields
+// already logged under it. Errors are nested as their message.
+func (l Logger) nestFields(fields map[string]interface{}) map[string]interface{} {
+	root := map[string]interface{}{}
+	if existing, ok := l.logger.Data[l.namespace[0]].(map[string]interface{}); ok {
+		// the map is shared with the loggers derived before, work on a copy
+		root = copyGroup(existing)
+	}
+
+	group := groupAt(root, l.namespace[1:])
+	for k, v := range fields {
+		group[k] = nestedValue(v)
+	}
+
+	return map[string]interface{}{l.namespace[0]: root}
+}
+
+// groupAt returns the nested map of fields at path, creating it if needed.
+func groupAt(fields map[string]interface{}, path []string) map[string]interface{} {
+	for _, name := range path {
+		group, ok := fields[name].(map[string]interface{})
+		if !ok {
+			group = map[string]interface{}{}
+			fields[name] = group
+		}
+		fields = group
+	}
+
+	return fields
+}
+
+// copyGroup deep copies the nested maps of fields, the values are shared.
+func copyGroup(fields map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if group, ok := v.(map[string]interface{}); ok {
+			v = copyGroup(group)
+		}
+		copied[k] = v
+	}
+
+	return copied
+}
+
+// nestedValue returns v as logged inside a nested object. The formatters only turn the errors
+// of the top-level fields into their message, nested ones would be logged as {}.
+func nestedValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+
+	return v
+}
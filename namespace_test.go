@@ -0,0 +1,42 @@
+package golog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithNamespace(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	db := logger.WithFields(map[string]interface{}{"service": "billing"}).WithNamespace("db")
+
+	db.WithFields(map[string]interface{}{"table": "users"}).
+		WithNamespace("pool").WithFields(map[string]interface{}{"size": 4}).
+		Infoln("nested")
+
+	entry := decodeEntry(t, buf)
+	if entry["service"] != "billing" {
+		t.Errorf("service = %v, want the field of the parent at the top level", entry["service"])
+	}
+	want := map[string]interface{}{"table": "users", "pool": map[string]interface{}{"size": 4.0}}
+	if !reflect.DeepEqual(entry["db"], want) {
+		t.Errorf("db = %v, want %v", entry["db"], want)
+	}
+}
+
+func TestWithNamespaceError(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	logger.WithNamespace("db").WithError(errors.New("boom")).Errorln("failed")
+
+	entry := decodeEntry(t, buf)
+	db, _ := entry["db"].(map[string]interface{})
+	if db[ErrorKey] != "boom" {
+		t.Errorf("db.%s = %#v, want the message of the error", ErrorKey, db[ErrorKey])
+	}
+	if chain, _ := db[ErrorChainKey].([]interface{}); len(chain) != 1 || chain[0] != "boom" {
+		t.Errorf("db.%s = %v, want [boom]", ErrorChainKey, db[ErrorChainKey])
+	}
+	if _, ok := entry[ErrorKey]; ok {
+		t.Errorf("%s present at the top level, want it nested", ErrorKey)
+	}
+}
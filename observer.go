@@ -0,0 +1,59 @@
+package golog
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Observer records the entries of a logger created by NewObserver, it is meant for tests
+// asserting on what was logged. It is safe for concurrent use.
+type Observer struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewObserver creates a new logger that doesn't write anything and records its entries in
+// the returned Observer instead.
+func NewObserver(l Level) (Logger, *Observer) {
+	observer := &Observer{}
+
+	return newOutputLogger(l, newOptions(nil), observerHook{observer: observer}), observer
+}
+
+// Entries returns the entries recorded so far, in the order they were logged.
+func (o *Observer) Entries() []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries := make([]Entry, len(o.entries))
+	copy(entries, o.entries)
+
+	return entries
+}
+
+// Reset discards the entries recorded so far.
+func (o *Observer) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries = nil
+}
+
+type observerHook struct {
+	observer *Observer
+}
+
+func (h observerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h observerHook) Fire(e *logrus.Entry) error {
+	h.observer.mu.Lock()
+	defer h.observer.mu.Unlock()
+
+	h.observer.entries = append(h.observer.entries, newEntry(e))
+	return nil
+}
+
+func (h observerHook) output() {}
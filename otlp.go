@@ -0,0 +1,324 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	otlpLogsPath      = "/v1/logs"
+	otlpScopeName     = "github.com/cvemprala/golog"
+	otlpBatchSize     = 512
+	otlpMaxBuffered   = 8192
+	otlpFlushInterval = time.Second
+	otlpDialTimeout   = 5 * time.Second
+	otlpExportTimeout = 10 * time.Second
+)
+
+// NewOTLP creates a new logger exporting its entries to an OpenTelemetry collector over
+// OTLP/HTTP with JSON encoding. endpoint is the collector URL, such as
+// "http://localhost:4318", "/v1/logs" is appended when it has no path. Entries are buffered
+// and exported in batches, the returned function exports the remaining ones and must be
+// called at shutdown. An error is returned if the collector can't be reached.
+func NewOTLP(l Level, endpoint string) (Logger, func(context.Context) error, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return Logger{}, nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Logger{}, nil, fmt.Errorf("golog: unsupported OTLP endpoint scheme %q", u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpLogsPath
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), map[string]string{"http": "80", "https": "443"}[u.Scheme])
+	}
+	conn, err := net.DialTimeout("tcp", host, otlpDialTimeout)
+	if err != nil {
+		return Logger{}, nil, fmt.Errorf("golog: OTLP endpoint unreachable: %w", err)
+	}
+	conn.Close()
+
+	exporter := newOTLPExporter(u.String())
+
+	return newOutputLogger(l, newOptions(nil), exporter), exporter.Shutdown, nil
+}
+
+// otlpExporter is a hook buffering entries as OTLP log records and exporting them from a
+// background goroutine.
+type otlpExporter struct {
+	endpoint string
+	client   *http.Client
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu       sync.Mutex
+	records  []otlpLogRecord
+	dropped  int
+	err      error
+	shutdown bool
+}
+
+func newOTLPExporter(endpoint string) *otlpExporter {
+	e := &otlpExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: otlpExportTimeout},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+
+	return e
+}
+
+func (e *otlpExporter) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (e *otlpExporter) Fire(entry *logrus.Entry) error {
+	record := newOTLPLogRecord(entry)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shutdown || len(e.records) >= otlpMaxBuffered {
+		e.dropped++
+		return nil
+	}
+	e.records = append(e.records, record)
+
+	return nil
+}
+
+func (e *otlpExporter) output() {}
+
+func (e *otlpExporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.flush(context.Background())
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// flush exports the buffered records, keeping the first error to report it on shutdown.
+func (e *otlpExporter) flush(ctx context.Context) error {
+	e.mu.Lock()
+	records := e.records
+	e.records = nil
+	e.mu.Unlock()
+
+	for len(records) > 0 {
+		n := len(records)
+		if n > otlpBatchSize {
+			n = otlpBatchSize
+		}
+		if err := e.export(ctx, records[:n]); err != nil {
+			e.mu.Lock()
+			if e.err == nil {
+				e.err = err
+			}
+			e.mu.Unlock()
+			return err
+		}
+		records = records[n:]
+	}
+
+	return nil
+}
+
+func (e *otlpExporter) export(ctx context.Context, records []otlpLogRecord) error {
+	body, err := json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("golog: OTLP export failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Shutdown stops the background export and exports the remaining records. It returns the
+// first export error, or an error reporting how many records were dropped.
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if e.shutdown {
+		e.mu.Unlock()
+		return nil
+	}
+	e.shutdown = true
+	e.mu.Unlock()
+
+	close(e.stop)
+	<-e.done
+	e.flush(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		return e.err
+	}
+	if e.dropped > 0 {
+		return fmt.Errorf("golog: dropped %d OTLP log records", e.dropped)
+	}
+	return nil
+}
+
+// Close shuts the exporter down when the logger is closed.
+func (e *otlpExporter) Close() error {
+	return e.Shutdown(context.Background())
+}
+
+// The types below are the subset of the OTLP/HTTP JSON encoding golog produces, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string          `json:"timeUnixNano"`
+	ObservedTimeUnixNano string          `json:"observedTimeUnixNano"`
+	SeverityNumber       int             `json:"severityNumber"`
+	SeverityText         string          `json:"severityText"`
+	Body                 otlpAnyValue    `json:"body"`
+	Attributes           []otlpAttribute `json:"attributes,omitempty"`
+	TraceID              string          `json:"traceId,omitempty"`
+	SpanID               string          `json:"spanId,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func newOTLPLogRecord(entry *logrus.Entry) otlpLogRecord {
+	level := fromLogrusLevel(entry.Level)
+	record := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(entry.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverityNumber(level),
+		SeverityText:         level.String(),
+		Body:                 newOTLPAnyValue(entry.Message),
+	}
+
+	for k, v := range entry.Data {
+		switch k {
+		case TraceIDKey:
+			record.TraceID = fmt.Sprint(v)
+		case SpanIDKey:
+			record.SpanID = fmt.Sprint(v)
+		default:
+			record.Attributes = append(record.Attributes, otlpAttribute{Key: k, Value: newOTLPAnyValue(v)})
+		}
+	}
+
+	return record
+}
+
+// otlpSeverityNumber maps a level to the first severity number of its OTLP range.
+func otlpSeverityNumber(l Level) int {
+	switch l {
+	case DEBUG:
+		return 5
+	case INFO:
+		return 9
+	case WARN:
+		return 13
+	default:
+		return 17
+	}
+}
+
+func newOTLPAnyValue(v interface{}) otlpAnyValue {
+	switch val := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &val}
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
+		s := fmt.Sprint(val)
+		return otlpAnyValue{IntValue: &s}
+	case float32:
+		f := float64(val)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &val}
+	case error:
+		s := val.Error()
+		return otlpAnyValue{StringValue: &s}
+	case fmt.Stringer:
+		s := val.String()
+		return otlpAnyValue{StringValue: &s}
+	default:
+		// structured values are sent as their JSON representation
+		s := fmt.Sprintf("%v", val)
+		if b, err := json.Marshal(val); err == nil {
+			s = string(b)
+		}
+		return otlpAnyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,35 @@
+package golog
+
+import "os"
+
+// Field keys of the process information options
+const (
+	HostnameKey = "hostname"
+	PIDKey      = "pid"
+)
+
+// WithHostname attaches the hostname of the machine to every entry under HostnameKey. It is
+// resolved once when the logger is created and falls back to "unknown" if it can't be.
+func WithHostname() Option {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+
+	return WithDefaultFields(map[string]interface{}{HostnameKey: hostname})
+}
+
+// WithPID attaches the process ID to every entry under PIDKey.
+func WithPID() Option {
+	return WithDefaultFields(map[string]interface{}{PIDKey: os.Getpid()})
+}
+
+// WithProcessInfo combines WithHostname and WithPID.
+func WithProcessInfo() Option {
+	hostname, pid := WithHostname(), WithPID()
+
+	return func(o *options) {
+		hostname(o)
+		pid(o)
+	}
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithFieldProcessor sets a function run over every field of an entry right before it is
+// emitted, defaults and fields added by golog included. It returns the key and value to log,
+// which allows renaming or transforming fields, and an empty key drops the field.
+func WithFieldProcessor(fn func(key string, value interface{}) (string, interface{})) Option {
+	return func(o *options) {
+		o.fieldProcessor = fn
+	}
+}
+
+// processFields returns a copy of entry with fn applied to its fields.
+func processFields(entry *logrus.Entry, fn func(key string, value interface{}) (string, interface{})) *logrus.Entry {
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if key, val := fn(k, v); key != "" {
+			data[key] = val
+		}
+	}
+
+	return &logrus.Entry{
+		Logger:  entry.Logger,
+		Data:    data,
+		Time:    entry.Time,
+		Context: entry.Context,
+	}
+}
+
+// WithNormalizedTimes logs time.Duration fields as float milliseconds instead of integer
+// nanoseconds, and time.Time fields in the format of the entries' timestamp, so that they
+// read the same whatever the format and are easy to aggregate. Only top-level fields are
+// normalized.
+func WithNormalizedTimes() Option {
+	return func(o *options) {
+		o.normalizeTimes = true
+	}
+}
+
+// normalizeTimes is a field processor normalizing durations and times, see
+// WithNormalizedTimes.
+func normalizeTimes(key string, value interface{}) (string, interface{}) {
+	switch val := value.(type) {
+	case time.Duration:
+		return key, durationMs(val)
+	case time.Time:
+		return key, val.Format(time.RFC3339Nano)
+	default:
+		return key, value
+	}
+}
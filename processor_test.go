@@ -0,0 +1,35 @@
+package golog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithNormalizedTimes(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	fields := map[string]interface{}{
+		"elapsed": 1500 * time.Microsecond,
+		"at":      at,
+		"nested":  map[string]interface{}{"elapsed": time.Second},
+	}
+
+	logger, buf := newTestLogger(t, INFO, WithNormalizedTimes())
+	logger.WithFields(fields).Infoln("normalized")
+
+	entry := decodeEntry(t, buf)
+	if entry["elapsed"] != 1.5 {
+		t.Errorf("elapsed = %v, want 1.5 milliseconds", entry["elapsed"])
+	}
+	if entry["at"] != "2024-03-01T12:30:00.123456789+01:00" {
+		t.Errorf("at = %v, want RFC 3339 with nanoseconds", entry["at"])
+	}
+	if nested, _ := entry["nested"].(map[string]interface{}); nested["elapsed"] != float64(time.Second) {
+		t.Errorf("nested.elapsed = %v, want nested fields left as is", nested["elapsed"])
+	}
+
+	plain, buf := newTestLogger(t, INFO)
+	plain.WithFields(fields).Infoln("as is")
+	if got := decodeEntry(t, buf)["elapsed"]; got != float64(1500*time.Microsecond) {
+		t.Errorf("elapsed = %v without WithNormalizedTimes, want nanoseconds", got)
+	}
+}
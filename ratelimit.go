@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithErrorRateLimit emits at most n error entries per window, the others are dropped and an
+// error entry reporting how many were, with the count under SuppressedCountKey, is logged
+// when the window closes. Entries below error level aren't affected. A n of 0 or less
+// disables the limit.
+func WithErrorRateLimit(n int, per time.Duration) Option {
+	return func(o *options) {
+		o.errorRateLimit = n
+		o.errorRateWindow = per
+	}
+}
+
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu         sync.Mutex
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+	}
+}
+
+// allow reports whether an entry can be emitted in the current window. When it suppresses
+// the first entry of a window, summary is called with the number of suppressed entries once
+// the window closes.
+func (r *rateLimiter) allow(summary func(suppressed int)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.start) >= r.window {
+		r.start = now
+		r.count = 0
+	}
+	if r.count < r.limit {
+		r.count++
+		return true
+	}
+
+	r.suppressed++
+	if r.suppressed == 1 {
+		time.AfterFunc(r.start.Add(r.window).Sub(now), func() {
+			r.mu.Lock()
+			suppressed := r.suppressed
+			r.suppressed = 0
+			r.mu.Unlock()
+
+			summary(suppressed)
+		})
+	}
+
+	return false
+}
+
+// errorSummary returns the function logging the number of error entries suppressed by the
+// rate limit, with the default fields of the logger.
+func (l Logger) errorSummary() func(suppressed int) {
+	logger, fields := l.logger.Logger, l.core.fields
+	return func(suppressed int) {
+		entry := logrus.NewEntry(logger).WithFields(fields).WithField(SuppressedCountKey, suppressed)
+		l.timestamped(entry).Log(logrus.ErrorLevel, fmt.Sprintf("suppressed %d errors", suppressed))
+	}
+}
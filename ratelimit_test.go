@@ -0,0 +1,60 @@
+package golog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// entryWriter sends each entry written to it on a channel.
+type entryWriter chan map[string]interface{}
+
+func (w entryWriter) Write(b []byte) (int, error) {
+	var e map[string]interface{}
+	if err := json.Unmarshal(b, &e); err != nil {
+		return 0, err
+	}
+
+	w <- e
+	return len(b), nil
+}
+
+func TestWithErrorRateLimit(t *testing.T) {
+	w := make(entryWriter, 10)
+	logger, err := NewWithOptions(INFO, w,
+		WithErrorRateLimit(2, 20*time.Millisecond),
+		WithDefaultFields(map[string]interface{}{"service": "api"}),
+		WithPID(),
+	)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		logger.Errorln("failed")
+	}
+	logger.Infoln("info")
+
+	var messages []interface{}
+	for i := 0; i < 3; i++ {
+		messages = append(messages, (<-w)["message"])
+	}
+	if messages[0] != "failed" || messages[1] != "failed" || messages[2] != "info" {
+		t.Errorf("messages = %v, want two errors and the info entry", messages)
+	}
+
+	select {
+	case summary := <-w:
+		if summary["message"] != "suppressed 3 errors" || summary["severity"] != "error" {
+			t.Errorf("summary = %v, want an error entry reporting 3 suppressed errors", summary)
+		}
+		if summary[SuppressedCountKey] != float64(3) {
+			t.Errorf("%s = %v, want 3", SuppressedCountKey, summary[SuppressedCountKey])
+		}
+		if summary["service"] != "api" || summary[PIDKey] == nil {
+			t.Errorf("summary = %v, want the default fields", summary)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no summary logged")
+	}
+}
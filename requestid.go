@@ -0,0 +1,39 @@
+package golog
+
+import (
+	"encoding/base32"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// IDFormat is the format of the request IDs generated by the middleware.
+type IDFormat int
+
+// ID formats supported
+const (
+	// UUIDv4 generates random UUIDs, it is the default.
+	UUIDv4 IDFormat = iota
+	// UUIDv7 generates time-ordered UUIDs, which index better in log backends.
+	UUIDv7
+	// Short generates 26 character URL-safe IDs, the base32 encoding of a random UUID.
+	Short
+)
+
+var shortIDEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRequestID returns a new request ID in the given format.
+func newRequestID(format IDFormat) string {
+	switch format {
+	case UUIDv7:
+		if id, err := uuid.NewV7(); err == nil {
+			return id.String()
+		}
+		return uuid.New().String()
+	case Short:
+		id := uuid.New()
+		return strings.ToLower(shortIDEncoding.EncodeToString(id[:]))
+	default:
+		return uuid.New().String()
+	}
+}
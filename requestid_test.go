@@ -0,0 +1,73 @@
+package golog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewRequestID(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      IDFormat
+		pattern     string
+		wantVersion uuid.Version
+	}{
+		{name: "UUIDv4", format: UUIDv4, pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, wantVersion: 4},
+		{name: "UUIDv7", format: UUIDv7, pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, wantVersion: 7},
+		{name: "Short", format: Short, pattern: `^[a-z2-7]{26}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := newRequestID(tt.format)
+			if !regexp.MustCompile(tt.pattern).MatchString(id) {
+				t.Fatalf("newRequestID() = %q, want it to match %s", id, tt.pattern)
+			}
+			if tt.wantVersion == 0 {
+				return
+			}
+			if parsed, err := uuid.Parse(id); err != nil || parsed.Version() != tt.wantVersion {
+				t.Errorf("uuid.Parse(%q) = %v, %v, want version %d", id, parsed.Version(), err, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestNewRequestIDUnique(t *testing.T) {
+	for _, format := range []IDFormat{UUIDv4, UUIDv7, Short} {
+		seen := make(map[string]bool)
+		for i := 0; i < 10000; i++ {
+			id := newRequestID(format)
+			if seen[id] {
+				t.Fatalf("format %d: newRequestID() returned %q twice", format, id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestNewRequestIDUUIDv7Time(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	id := uuid.MustParse(newRequestID(UUIDv7))
+
+	sec, nsec := id.Time().UnixTime()
+	if at := time.Unix(sec, nsec); at.Before(before) || at.After(time.Now().Add(time.Second)) {
+		t.Errorf("UUIDv7 time = %v, want about now", at)
+	}
+}
+
+func TestMiddlewareRequestIDFormat(t *testing.T) {
+	handler, _ := newTestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), MiddlewareOptions{RequestIDFormat: Short})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if id := w.Header().Get("Request-ID"); !regexp.MustCompile(`^[a-z2-7]{26}$`).MatchString(id) {
+		t.Errorf("Request-ID = %q, want a short ID", id)
+	}
+}
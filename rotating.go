@@ -0,0 +1,21 @@
+package golog
+
+import "gopkg.in/natefinch/lumberjack.v2"
+
+// NewRotating creates a new logger writing to filename, which is rotated once it reaches
+// maxSizeMB megabytes. At most maxBackups rotated files are kept, for at most maxAgeDays
+// days, a zero value keeps them all. Rotation is handled by gopkg.in/natefinch/lumberjack.v2.
+// Logger.Close closes the file.
+func NewRotating(l Level, filename string, maxSizeMB, maxBackups, maxAgeDays int) Logger {
+	writer := &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAgeDays,
+	}
+
+	logger := New(l, writer)
+	logger.core.addCloser(writer.Close)
+
+	return logger
+}
@@ -0,0 +1,45 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewRotating(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	logger := NewRotating(INFO, filename, 1, 3, 0)
+	defer logger.Close()
+
+	// a bit over the 1MB limit, for the file to be rotated once
+	msg := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		logger.Infoln(msg)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	var backups []string
+	for _, f := range files {
+		if f.Name() != "app.log" {
+			backups = append(backups, f.Name())
+		}
+	}
+	if len(backups) == 0 {
+		t.Fatalf("no backup file in %s, got %d files", dir, len(files))
+	}
+	for _, name := range backups {
+		if !strings.HasPrefix(name, "app-") || !strings.HasSuffix(name, ".log") {
+			t.Errorf("unexpected backup file name %q", name)
+		}
+	}
+	if _, err := os.Stat(filename); err != nil {
+		t.Errorf("current log file: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package golog
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+)
+
+// DefaultMaxBodyLogBytes is the RoundTripperOptions.MaxBodyLogBytes set by NewRoundTripper.
+const DefaultMaxBodyLogBytes = 64 << 10
+
+// RoundTripperOptions struct
+type RoundTripperOptions struct {
+	LogRequestBody  bool
+	LogResponseBody bool
+	// RedactHeaders lists the headers whose values are redacted in logs, see
+	// MiddlewareOptions.RedactHeaders.
+	RedactHeaders []string
+	// MaxBodyLogBytes bounds the size of the logged request and response bodies. Bodies
+	// declaring a larger Content-Length aren't read, only "contentLength" being logged, and
+	// at most MaxBodyLogBytes of the others are, "bodyTruncated" and "bodyBytesRead" being
+	// logged for the longer ones. Either way the body is sent or returned in full. Zero means
+	// no limit.
+	MaxBodyLogBytes int64
+}
+
+// LoggingRoundTripper wraps an http.RoundTripper to log the outbound requests, the client
+// side counterpart of NewMiddleware. The request ID found in the request context is sent in
+// the Request-ID header so the request can be traced across services. Each round trip is
+// logged as an "http_client_request" entry, at error level when it fails.
+type LoggingRoundTripper struct {
+	next    http.RoundTripper
+	logger  Logger
+	options RoundTripperOptions
+}
+
+// NewRoundTripper creates a new LoggingRoundTripper wrapping next, or http.DefaultTransport
+// if next is nil. Bodies up to DefaultMaxBodyLogBytes are logged.
+func NewRoundTripper(next http.RoundTripper, logger Logger) *LoggingRoundTripper {
+	return NewRoundTripperWithOptions(next, logger, RoundTripperOptions{
+		LogRequestBody:  true,
+		LogResponseBody: true,
+		MaxBodyLogBytes: DefaultMaxBodyLogBytes,
+	})
+}
+
+// NewRoundTripperWithOptions creates a new LoggingRoundTripper, see NewRoundTripper.
+func NewRoundTripperWithOptions(next http.RoundTripper, logger Logger, options RoundTripperOptions) *LoggingRoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if logger.IsZero() {
+		logger = New(INFO, os.Stdout)
+	}
+
+	return &LoggingRoundTripper{
+		next:    next,
+		logger:  logger,
+		options: options,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.logger.now()
+	logger := t.logger
+
+	// the caller's request must not be modified, work on a copy
+	req = req.Clone(req.Context())
+	if requestID, ok := RequestIDFromContext(req.Context()); ok {
+		req.Header.Set("Request-ID", requestID)
+		logger = logger.WithFields(map[string]interface{}{string(ContextKeyRequestID): requestID})
+	}
+
+	var requestBody interface{}
+	if t.options.LogRequestBody {
+		requestBody, req.Body, logger = t.logBody(logger, req.Header, req.ContentLength, req.Body)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	logger = logger.WithFields(map[string]interface{}{
+		"method":      req.Method,
+		"url":         req.URL.String(),
+		"header":      redactHeaders(req.Header, t.options.RedactHeaders),
+		"requestBody": requestBody,
+	})
+	if err != nil {
+		logRoundTripError(logger, start, err)
+		return nil, err
+	}
+
+	var responseBody interface{}
+	if t.options.LogResponseBody {
+		responseBody, resp.Body, logger = t.logBody(logger, resp.Header, resp.ContentLength, resp.Body)
+	}
+
+	duration := logger.since(start)
+	logger.WithFields(map[string]interface{}{
+		"duration":       duration,
+		"durationMs":     durationMs(duration),
+		"status":         resp.StatusCode,
+		"responseHeader": redactHeaders(resp.Header, t.options.RedactHeaders),
+		"responseBody":   responseBody,
+	}).Debugln("http_client_request")
+
+	return resp, nil
+}
+
+// logBody reads a request or response body for logging and returns it parsed, along with a
+// body reading the original one from its start. Binary, streamed and oversized bodies aren't
+// logged, and failing to read the body doesn't fail the round trip, the error is left for the
+// reader of the returned body.
+func (t *LoggingRoundTripper) logBody(logger Logger, header http.Header, contentLength int64, body io.ReadCloser) (interface{}, io.ReadCloser, Logger) {
+	if body == nil || body == http.NoBody {
+		return nil, body, logger
+	}
+	if skipRoundTripBody(header, contentLength, t.options.MaxBodyLogBytes) {
+		return nil, body, logger.WithFields(skippedBodyFields(contentLength))
+	}
+
+	buf, body, truncated, err := readLimitedBody(body, t.options.MaxBodyLogBytes)
+	switch {
+	case err != nil:
+		return nil, body, logger.WithFields(map[string]interface{}{"bodyError": err})
+	case truncated:
+		return nil, body, logger.WithFields(truncatedBodyFields(contentLength, len(buf)))
+	default:
+		parsed, logger := parseBody(logger, header, buf, t.options.MaxBodyLogBytes)
+		return parsed, body, logger
+	}
+}
+
+// skipRoundTripBody reports whether a body shouldn't be read for logging, because it isn't
+// textual, it is an event stream that would block the round trip until it ends, or its
+// declared length exceeds maxBytes.
+func skipRoundTripBody(header http.Header, contentLength, maxBytes int64) bool {
+	if maxBytes > 0 && contentLength > maxBytes {
+		return true
+	}
+
+	contentType := header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/event-stream" {
+		return true
+	}
+	return bodyKindOf(contentType) == bodyKindBinary
+}
+
+func logRoundTripError(logger Logger, start time.Time, err error) {
+	duration := logger.since(start)
+	logger.WithFields(map[string]interface{}{
+		"duration":   duration,
+		"durationMs": durationMs(duration),
+		ErrorKey:     err,
+	}).Errorln("http_client_request")
+}
@@ -0,0 +1,232 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripEntry returns the single entry recorded by observer for a round trip.
+func roundTripEntry(t *testing.T, observer *Observer) Entry {
+	t.Helper()
+
+	entries := observer.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	return entries[0]
+}
+
+func TestRoundTripper(t *testing.T) {
+	var gotRequestID, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID = r.Header.Get("Request-ID")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	logger, observer := NewObserver(DEBUG)
+	client := &http.Client{Transport: NewRoundTripper(nil, logger)}
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/users", strings.NewReader(`{"name":"n"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != `{"id":1}` {
+		t.Errorf("response body = %q, want it untouched", body)
+	}
+	if gotBody != `{"name":"n"}` {
+		t.Errorf("request body = %q, want it untouched", gotBody)
+	}
+	if gotRequestID != "req-1" {
+		t.Errorf("Request-ID header = %q, want req-1", gotRequestID)
+	}
+
+	entry := roundTripEntry(t, observer)
+	if entry.Message != "http_client_request" || entry.Level != DEBUG {
+		t.Errorf("message, level = %q, %v, want http_client_request, %v", entry.Message, entry.Level, DEBUG)
+	}
+	if entry.Fields["status"] != http.StatusCreated {
+		t.Errorf("status = %v, want %d", entry.Fields["status"], http.StatusCreated)
+	}
+	if entry.Fields[string(ContextKeyRequestID)] != "req-1" {
+		t.Errorf("requestId = %v, want req-1", entry.Fields[string(ContextKeyRequestID)])
+	}
+	if m, ok := entry.Fields["responseBody"].(map[string]interface{}); !ok || m["id"] == nil {
+		t.Errorf("responseBody = %v, want the parsed body", entry.Fields["responseBody"])
+	}
+	if m, ok := entry.Fields["requestBody"].(map[string]interface{}); !ok || m["name"] != "n" {
+		t.Errorf("requestBody = %v, want the parsed body", entry.Fields["requestBody"])
+	}
+}
+
+func TestRoundTripperStreamedBodies(t *testing.T) {
+	for _, contentType := range []string{"text/event-stream", "application/octet-stream"} {
+		t.Run(contentType, func(t *testing.T) {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", contentType)
+				w.Write([]byte("first"))
+				w.(http.Flusher).Flush()
+				<-release
+				w.Write([]byte(" last"))
+			}))
+			defer server.Close()
+			defer close(release)
+
+			logger, observer := NewObserver(DEBUG)
+			client := &http.Client{Transport: NewRoundTripper(nil, logger)}
+
+			done := make(chan *http.Response)
+			go func() {
+				resp, err := client.Get(server.URL)
+				if err != nil {
+					t.Errorf("Get() error = %v", err)
+				}
+				done <- resp
+			}()
+
+			var resp *http.Response
+			select {
+			case resp = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("RoundTrip waited for the end of the body")
+			}
+			if resp == nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			entry := roundTripEntry(t, observer)
+			if entry.Fields["responseBody"] != nil {
+				t.Errorf("responseBody = %v, want none", entry.Fields["responseBody"])
+			}
+			if _, ok := entry.Fields["contentLength"]; ok {
+				t.Errorf("contentLength = %v, want none without a declared length", entry.Fields["contentLength"])
+			}
+
+			release <- struct{}{}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != "first last" {
+				t.Errorf("body = %q, want first last", body)
+			}
+		})
+	}
+}
+
+func TestRoundTripperMaxBodyLogBytes(t *testing.T) {
+	large := strings.Repeat("a", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(large[:50]))
+		w.(http.Flusher).Flush()
+		// chunked, without a declared length
+		w.Write([]byte(large[50:]))
+	}))
+	defer server.Close()
+
+	logger, observer := NewObserver(DEBUG)
+	client := &http.Client{Transport: NewRoundTripperWithOptions(nil, logger, RoundTripperOptions{
+		LogResponseBody: true,
+		MaxBodyLogBytes: 10,
+	})}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != large {
+		t.Errorf("body = %q, want the whole body", body)
+	}
+	entry := roundTripEntry(t, observer)
+	if entry.Fields["responseBody"] != nil {
+		t.Errorf("responseBody = %v, want none", entry.Fields["responseBody"])
+	}
+	if _, ok := entry.Fields["contentLength"]; ok {
+		t.Errorf("contentLength = %v, want none without a declared length", entry.Fields["contentLength"])
+	}
+	if entry.Fields["bodyTruncated"] != true || entry.Fields["bodyBytesRead"] != 11 {
+		t.Errorf("bodyTruncated, bodyBytesRead = %v, %v, want true, 11", entry.Fields["bodyTruncated"], entry.Fields["bodyBytesRead"])
+	}
+}
+
+func TestRoundTripperBodyReadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		// the connection is closed before the declared length is sent
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"id\":")
+		buf.Flush()
+		conn.Close()
+	}))
+	defer server.Close()
+
+	logger, observer := NewObserver(DEBUG)
+	client := &http.Client{Transport: NewRoundTripper(nil, logger)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v, want the response", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err == nil {
+		t.Errorf("reading the body succeeded, want the read error")
+	}
+	if string(body) != `{"id":` {
+		t.Errorf("body = %q, want what was received", body)
+	}
+	entry := roundTripEntry(t, observer)
+	if entry.Fields["bodyError"] == nil {
+		t.Errorf("bodyError missing")
+	}
+	if entry.Fields["status"] != http.StatusOK {
+		t.Errorf("status = %v, want %d", entry.Fields["status"], http.StatusOK)
+	}
+}
+
+// failingRoundTripper fails every round trip.
+type failingRoundTripper struct{}
+
+func (failingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRoundTripperError(t *testing.T) {
+	logger, observer := NewObserver(DEBUG)
+	client := &http.Client{Transport: NewRoundTripper(failingRoundTripper{}, logger)}
+
+	if _, err := client.Get("http://example.com"); err == nil {
+		t.Fatalf("Get() succeeded, want the round trip error")
+	}
+
+	entry := roundTripEntry(t, observer)
+	if entry.Message != "http_client_request" || entry.Level != ERROR {
+		t.Errorf("message, level = %q, %v, want http_client_request, %v", entry.Message, entry.Level, ERROR)
+	}
+	if err, _ := entry.Fields[ErrorKey].(error); err == nil || err.Error() != "connection refused" {
+		t.Errorf("error = %v, want connection refused", entry.Fields[ErrorKey])
+	}
+}
@@ -0,0 +1,47 @@
+package golog
+
+import "sync"
+
+// sampler counts messages per key and lets every rate-th one through.
+type sampler struct {
+	rate uint64
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+func newSampler(rate int) *sampler {
+	if rate <= 1 {
+		return nil
+	}
+
+	return &sampler{
+		rate:   uint64(rate),
+		counts: make(map[string]uint64),
+	}
+}
+
+// sample records a message for key and reports whether it should be emitted, along with
+// the number of messages suppressed since the previous emitted one.
+func (s *sampler) sample(key string) (uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.counts[key]
+	s.counts[key] = n + 1
+
+	if n%s.rate != 0 {
+		return 0, false
+	}
+	if n == 0 {
+		return 0, true
+	}
+	return s.rate - 1, true
+}
+
+// WithSampleKey returns a new logger whose messages are sampled under key rather than per
+// level. It has no effect unless the logger was created with WithSampling.
+func (l Logger) WithSampleKey(key string) Logger {
+	l.sampleKey = key
+	return l
+}
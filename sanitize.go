@@ -0,0 +1,215 @@
+package golog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// sanitizeFields replaces the values that can't be marshaled to JSON by their string
+// representation, so that a single bad field doesn't make the formatter drop the entry. The
+// fields are returned as is when they all marshal, or else copied before being modified.
+func sanitizeFields(fields map[string]interface{}) map[string]interface{} {
+	var marshalErrors map[string]string
+	for k, v := range fields {
+		if err := checkMarshal(v); err != nil {
+			if marshalErrors == nil {
+				marshalErrors = make(map[string]string)
+			}
+			marshalErrors[k] = err.Error()
+		}
+	}
+	if marshalErrors == nil {
+		return fields
+	}
+
+	sanitized := copyFields(fields, 1)
+	for k := range marshalErrors {
+		sanitized[k] = fmt.Sprintf("%v", fields[k])
+	}
+	sanitized[FieldMarshalErrorKey] = marshalErrors
+
+	return sanitized
+}
+
+// checkMarshal returns the error marshaling v to JSON would produce, including a panic
+// raised by a MarshalJSON method. Values are only marshaled when they may fail to, the common
+// scalars, maps and slices are checked without being marshaled.
+func checkMarshal(v interface{}) (err error) {
+	switch val := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		time.Time, time.Duration, error:
+		// always marshal fine, errors are serialized through their Error method by logrus
+		return nil
+	case float64:
+		if !math.IsNaN(val) && !math.IsInf(val, 0) {
+			return nil
+		}
+	case float32:
+		if f := float64(val); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return nil
+		}
+	default:
+		if marshalSafeValue(reflect.ValueOf(v), 0) {
+			return nil
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic marshaling field: %v", r)
+		}
+	}()
+
+	_, err = json.Marshal(v)
+	return err
+}
+
+// maxMarshalCheckDepth bounds how deep marshalSafeValue looks into a value, deeper values and
+// cyclic ones are marshaled instead.
+const maxMarshalCheckDepth = 16
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// marshalSafeValue reports whether v is known to marshal to JSON without an error. A false
+// result means v has to be marshaled to tell, as it holds a channel, a function, a complex
+// number, a NaN or infinite float, or a type with its own marshaling method.
+func marshalSafeValue(v reflect.Value, depth int) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if depth > maxMarshalCheckDepth {
+		return false
+	}
+	if marshalSafeType(v.Type()) {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return !math.IsNaN(f) && !math.IsInf(f, 0)
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+		if hasMarshalMethod(v.Type()) {
+			return false
+		}
+		return marshalSafeValue(v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		if hasMarshalMethod(v.Type()) {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !marshalSafeValue(v.Index(i), depth+1) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if hasMarshalMethod(v.Type()) || !marshalSafeMapKey(v.Type().Key()) {
+			return false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if !marshalSafeValue(iter.Value(), depth+1) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if hasMarshalMethod(v.Type()) {
+			return false
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !marshalSafeValue(v.Field(i), depth+1) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// marshalSafeTypes caches the results of marshalSafeType.
+var marshalSafeTypes sync.Map
+
+// marshalSafeType reports whether every value of type t marshals to JSON without an error,
+// so that its values don't have to be checked, as for http.Header.
+func marshalSafeType(t reflect.Type) bool {
+	if safe, ok := marshalSafeTypes.Load(t); ok {
+		return safe.(bool)
+	}
+
+	// recursive types are checked value by value
+	marshalSafeTypes.Store(t, false)
+	safe := computeMarshalSafeType(t)
+	marshalSafeTypes.Store(t, safe)
+
+	return safe
+}
+
+func computeMarshalSafeType(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	if hasMarshalMethod(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return marshalSafeType(t.Elem())
+	case reflect.Map:
+		return marshalSafeMapKey(t.Key()) && marshalSafeType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !marshalSafeType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		// floats may be NaN and interfaces may hold anything, their values are checked
+		return false
+	}
+}
+
+// marshalSafeMapKey reports whether encoding/json supports map keys of type t without
+// calling a marshaling method.
+func marshalSafeMapKey(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textMarshalerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasMarshalMethod reports whether encoding/json marshals values of type t with a method,
+// which may fail or panic.
+func hasMarshalMethod(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return t.Implements(jsonMarshalerType) || pt.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
@@ -0,0 +1,98 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// panickingMarshaler panics when marshaled to JSON.
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) {
+	panic("boom")
+}
+
+// failingMarshaler fails to marshal to JSON.
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("not marshalable")
+}
+
+func TestWithFieldsUnmarshalableValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantError string
+	}{
+		{name: "channel", value: make(chan int), wantError: "unsupported type"},
+		{name: "func", value: func() {}, wantError: "unsupported type"},
+		{name: "panicking marshaler", value: panickingMarshaler{}, wantError: "panic marshaling field: boom"},
+		{name: "failing marshaler", value: failingMarshaler{}, wantError: "not marshalable"},
+		{name: "NaN", value: math.NaN(), wantError: "unsupported value"},
+		{name: "nested channel", value: map[string]interface{}{"c": []interface{}{make(chan int)}}, wantError: "unsupported type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger(t, INFO)
+			logger.WithFields(map[string]interface{}{"bad": tt.value, "good": "kept"}).Infoln("logged")
+
+			entry := decodeEntry(t, buf)
+			if entry["good"] != "kept" {
+				t.Errorf("good = %v, want kept", entry["good"])
+			}
+			if _, ok := entry["bad"].(string); !ok {
+				t.Errorf("bad = %#v, want its string representation", entry["bad"])
+			}
+			marshalErrors, _ := entry[FieldMarshalErrorKey].(map[string]interface{})
+			if msg, _ := marshalErrors["bad"].(string); !strings.Contains(msg, tt.wantError) {
+				t.Errorf("%s[bad] = %q, want it to contain %q", FieldMarshalErrorKey, msg, tt.wantError)
+			}
+			if _, ok := marshalErrors["good"]; ok {
+				t.Errorf("%s has an error for a valid field", FieldMarshalErrorKey)
+			}
+		})
+	}
+}
+
+func TestCheckMarshalValidValues(t *testing.T) {
+	values := []interface{}{
+		"s", 1, 1.5, float32(2.5), true, time.Now(), time.Second, errors.New("e"),
+		http.Header{"Accept": {"*/*"}},
+		map[string]interface{}{"id": json.Number("1234567890123456789"), "tags": []interface{}{"a", 1.0}},
+		[]string{"a"},
+		struct {
+			Name string
+			At   time.Time
+		}{Name: "n"},
+		&struct{ N int }{N: 1},
+		map[int]string{1: "a"},
+		json.RawMessage(`{"a":1}`),
+	}
+
+	for _, v := range values {
+		if err := checkMarshal(v); err != nil {
+			t.Errorf("checkMarshal(%#v) error = %v", v, err)
+		}
+	}
+}
+
+func TestCheckMarshalSkipsSafeValues(t *testing.T) {
+	values := []interface{}{
+		1.5,
+		http.Header{"Accept": {"*/*"}, "User-Agent": {"test"}},
+		[]string{"a", "b"},
+	}
+
+	for _, v := range values {
+		if allocs := testing.AllocsPerRun(100, func() { checkMarshal(v) }); allocs != 0 {
+			t.Errorf("checkMarshal(%#v) allocations = %v, want 0", v, allocs)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package golog
+
+import "sync"
+
+// ScopedLogger is a mutable logger accumulating fields for all its subsequent log calls, for
+// handlers discovering context as they run. Logger itself stays immutable, only the scope
+// returned by Logger.Scope is modified in place. It is safe for concurrent use.
+type ScopedLogger struct {
+	mu     sync.RWMutex
+	logger Logger
+}
+
+// Scope returns a new scope starting with the fields of the logger.
+func (l Logger) Scope() *ScopedLogger {
+	return &ScopedLogger{logger: l}
+}
+
+// AddField adds a field to every subsequent entry of the scope.
+func (s *ScopedLogger) AddField(key string, value interface{}) {
+	s.AddFields(map[string]interface{}{key: value})
+}
+
+// AddFields adds fields to every subsequent entry of the scope.
+func (s *ScopedLogger) AddFields(fields map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger = s.logger.WithFields(fields)
+}
+
+// Logger returns an immutable logger with the fields accumulated so far.
+func (s *ScopedLogger) Logger() Logger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.logger
+}
+
+func (s *ScopedLogger) Debugln(msg string) {
+	s.Logger().Debugln(msg)
+}
+
+func (s *ScopedLogger) Infoln(msg string) {
+	s.Logger().Infoln(msg)
+}
+
+func (s *ScopedLogger) Warnln(msg string) {
+	s.Logger().Warnln(msg)
+}
+
+func (s *ScopedLogger) Errorln(msg string) {
+	s.Logger().Errorln(msg)
+}
+
+// Logln logs the message at the given level.
+func (s *ScopedLogger) Logln(level Level, msg string) {
+	s.Logger().Logln(level, msg)
+}
+
+// Logf formats the message according to the format specifier and logs it at the given level.
+func (s *ScopedLogger) Logf(level Level, format string, args ...interface{}) {
+	s.Logger().Logf(level, format, args...)
+}
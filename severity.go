@@ -0,0 +1,46 @@
+package golog
+
+// SeverityNumberKey holds the numeric severity of the entry, see WithSeverityNumber.
+const SeverityNumberKey = "severityNumber"
+
+// SeverityScale is the scale of the numbers logged by WithSeverityNumber.
+type SeverityScale int
+
+// Severity scales supported
+const (
+	// SeverityScaleOTLP follows the OpenTelemetry severity numbers: 5 for DEBUG, 9 for INFO,
+	// 13 for WARN and 17 for ERROR, the first number of each range.
+	SeverityScaleOTLP SeverityScale = iota
+	// SeverityScaleGCP follows the Google Cloud Logging LogSeverity enum: 100 for DEBUG, 200
+	// for INFO, 400 for WARN and 500 for ERROR.
+	SeverityScaleGCP
+)
+
+// WithSeverityNumber adds the severity of each entry as a number under SeverityNumberKey,
+// alongside the level string, for log systems querying severities by range.
+func WithSeverityNumber(scale SeverityScale) Option {
+	return func(o *options) {
+		o.severityScale = &scale
+	}
+}
+
+// severityNumber returns the number of level in the scale.
+func (s SeverityScale) severityNumber(l Level) int {
+	if s == SeverityScaleGCP {
+		return gcpSeverityNumber(l)
+	}
+	return otlpSeverityNumber(l)
+}
+
+func gcpSeverityNumber(l Level) int {
+	switch l {
+	case DEBUG:
+		return 100
+	case INFO:
+		return 200
+	case WARN:
+		return 400
+	default:
+		return 500
+	}
+}
@@ -0,0 +1,36 @@
+package golog
+
+import "testing"
+
+func TestWithSeverityNumber(t *testing.T) {
+	tests := []struct {
+		name  string
+		scale SeverityScale
+		want  map[Level]float64
+	}{
+		{name: "OTLP", scale: SeverityScaleOTLP, want: map[Level]float64{DEBUG: 5, INFO: 9, WARN: 13, ERROR: 17}},
+		{name: "GCP", scale: SeverityScaleGCP, want: map[Level]float64{DEBUG: 100, INFO: 200, WARN: 400, ERROR: 500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for level, want := range tt.want {
+				logger, buf := newTestLogger(t, DEBUG, WithSeverityNumber(tt.scale))
+				logger.Logln(level, "hello")
+
+				if got := decodeEntry(t, buf)[SeverityNumberKey]; got != want {
+					t.Errorf("level %v: %s = %v, want %v", level, SeverityNumberKey, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithoutSeverityNumber(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	logger.Infoln("hello")
+
+	if _, ok := decodeEntry(t, buf)[SeverityNumberKey]; ok {
+		t.Errorf("%s present without WithSeverityNumber", SeverityNumberKey)
+	}
+}
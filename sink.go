@@ -0,0 +1,146 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink receives the entries of a logger created by NewWithSinks, for outputs other than byte
+// streams such as the platform native logging services. Sinks implementing io.Closer are
+// closed by Logger.Close.
+type Sink interface {
+	Write(entry Entry) error
+}
+
+// NewWithSinks creates a new logger handing its entries to the given sinks only. New writes
+// through a WriterSink.
+func NewWithSinks(l Level, sinks ...Sink) Logger {
+	outputs := make([]outputHook, len(sinks))
+	for i, sink := range sinks {
+		outputs[i] = &sinkHook{sink: sink}
+	}
+
+	return newOutputLogger(l, newOptions(nil), outputs...)
+}
+
+// outputHook is implemented by the hooks writing the entries out, in place of the writer of
+// the underlying logger which discards them. They fire after the hooks added with AddHook,
+// so that they see the changes these make to the entries.
+type outputHook interface {
+	Hook
+	output()
+}
+
+// writerOutput is implemented by the output hooks writing to the writer replaced by SetOutput.
+type writerOutput interface {
+	outputHook
+	withWriter(w io.Writer) outputHook
+}
+
+// discardFormatter is the formatter of the underlying loggers, whose entries are formatted
+// by their output hooks.
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+type sinkHook struct {
+	sink Sink
+}
+
+func (h *sinkHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *sinkHook) Fire(e *logrus.Entry) error {
+	if writerSink, ok := h.sink.(*WriterSink); ok {
+		return writerSink.write(e)
+	}
+	return h.sink.Write(newEntry(e))
+}
+
+func (h *sinkHook) Close() error {
+	if closer, ok := h.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (h *sinkHook) output() {}
+
+// writerHook writes the entries to the writer of a logger created by New, the one replaced
+// by SetOutput.
+type writerHook struct {
+	sinkHook
+}
+
+// withWriter returns a hook writing to w with the same format.
+func (h *writerHook) withWriter(w io.Writer) outputHook {
+	return &writerHook{sinkHook{sink: h.sink.(*WriterSink).withWriter(w)}}
+}
+
+// WriterSink is a Sink writing entries to an io.Writer, formatted as New does.
+type WriterSink struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	formatter logrus.Formatter
+}
+
+// NewWriterSink creates a new sink writing to w. Only the options configuring the format of
+// the entries, such as WithFormat and WithFieldNames, apply to it.
+func NewWriterSink(w io.Writer, opts ...Option) *WriterSink {
+	return &WriterSink{
+		writer:    w,
+		formatter: newFormatter(newOptions(opts), w),
+	}
+}
+
+// Write implements Sink.
+func (s *WriterSink) Write(entry Entry) error {
+	return s.write(&logrus.Entry{
+		Data:    entry.Fields,
+		Time:    entry.Time,
+		Level:   entry.Level.toLogrusLevel(),
+		Message: entry.Message,
+	})
+}
+
+// formatBuffers holds the buffers the entries are formatted into, logrus only provides one
+// to the formatter of the underlying logger.
+var formatBuffers = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func (s *WriterSink) write(e *logrus.Entry) error {
+	buf := formatBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer formatBuffers.Put(buf)
+
+	// the formatters write into the buffer of the entry when it has one
+	e.Buffer = buf
+	b, err := s.formatter.Format(e)
+	e.Buffer = nil
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.writer.Write(b)
+	return err
+}
+
+// withWriter returns a sink writing to w with the same format.
+func (s *WriterSink) withWriter(w io.Writer) *WriterSink {
+	return &WriterSink{
+		writer:    w,
+		formatter: s.formatter,
+	}
+}
@@ -0,0 +1,128 @@
+package golog
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingSink records the entries written to it.
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (s *recordingSink) Write(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// fieldHook adds a field to the entries it is fired for.
+type fieldHook struct {
+	key, value string
+}
+
+func (h fieldHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h fieldHook) Fire(e *logrus.Entry) error {
+	e.Data[h.key] = h.value
+	return nil
+}
+
+func TestWriterSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewWriterSink(buf, WithFieldNames("time", "level", "msg"))
+
+	err := sink.Write(Entry{
+		Time:    time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   WARN,
+		Message: "hello",
+		Fields:  map[string]interface{}{"k": "v"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	e := decodeEntry(t, buf)
+	if e["msg"] != "hello" || e["level"] != "warning" || e["k"] != "v" {
+		t.Errorf("entry = %v, want the message, level and field of the written one", e)
+	}
+	if e["time"] != "2023-01-02T03:04:05Z" {
+		t.Errorf("time = %v, want the one of the written entry", e["time"])
+	}
+}
+
+func TestNewWithSinks(t *testing.T) {
+	first, second := &recordingSink{}, &recordingSink{}
+	logger := NewWithSinks(INFO, first, second)
+
+	logger.Debugln("debug")
+	logger.WithFields(map[string]interface{}{"k": "v"}).Infoln("info")
+
+	for _, sink := range []*recordingSink{first, second} {
+		if len(sink.entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(sink.entries))
+		}
+		e := sink.entries[0]
+		if e.Message != "info" || e.Level != INFO || e.Fields["k"] != "v" {
+			t.Errorf("entry = %+v, want the info one with its field", e)
+		}
+	}
+}
+
+func TestNewWritesThroughSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(INFO, buf)
+	logger.AddHook(fieldHook{key: "hooked", value: "yes"})
+
+	logger.Infoln("first")
+	logger.Infoln("second")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if e["hooked"] != "yes" {
+			t.Errorf("entry = %v, want the field added by the hook", e)
+		}
+	}
+}
+
+func TestSetOutputOfClone(t *testing.T) {
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := New(INFO, first)
+	clone := logger.Clone()
+	clone.SetOutput(second)
+
+	logger.Infoln("original")
+	clone.Infoln("clone")
+
+	if got := decodeEntry(t, first)["message"]; got != "original" {
+		t.Errorf("original writer got %v, want only the original entry", got)
+	}
+	if got := decodeEntry(t, second)["message"]; got != "clone" {
+		t.Errorf("clone writer got %v, want only the clone entry", got)
+	}
+}
+
+func TestAddHookBeforeSinks(t *testing.T) {
+	sink := &recordingSink{}
+	logger := NewWithSinks(INFO, sink)
+	logger.AddHook(fieldHook{key: "hooked", value: "yes"})
+
+	logger.Infoln("info")
+
+	if got := sink.entries[0].Fields; !reflect.DeepEqual(got, map[string]interface{}{"hooked": "yes"}) {
+		t.Errorf("fields = %v, want the one added by the hook", got)
+	}
+}
@@ -0,0 +1,132 @@
+//go:build go1.21
+// +build go1.21
+
+package golog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler routing records through a golog Logger, so that libraries
+// using log/slog share golog's format and output. Attributes are logged as fields, those
+// inside groups as nested objects.
+type SlogHandler struct {
+	logger Logger
+	attrs  map[string]interface{}
+	groups []string
+}
+
+// NewSlogHandler creates a new slog handler backed by logger, as in
+// slog.New(golog.NewSlogHandler(logger)).
+func NewSlogHandler(logger Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+		attrs:  map[string]interface{}{},
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(fromSlogLevel(level))
+}
+
+// Handle implements slog.Handler.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := copyGroup(h.attrs)
+	if r.NumAttrs() > 0 {
+		group := groupAt(fields, h.groups)
+		r.Attrs(func(a slog.Attr) bool {
+			addAttr(group, a, len(h.groups) > 0)
+			return true
+		})
+	}
+
+	logger := h.logger
+	if !r.Time.IsZero() {
+		logger.logger = logger.logger.WithTime(r.Time)
+	}
+	logger.WithFields(fields).Logln(fromSlogLevel(r.Level), r.Message)
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := copyGroup(h.attrs)
+	group := groupAt(fields, h.groups)
+	for _, a := range attrs {
+		addAttr(group, a, len(h.groups) > 0)
+	}
+
+	return &SlogHandler{
+		logger: h.logger,
+		attrs:  fields,
+		groups: h.groups,
+	}
+}
+
+// WithGroup implements slog.Handler.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+
+	return &SlogHandler{
+		logger: h.logger,
+		attrs:  h.attrs,
+		groups: append(groups, name),
+	}
+}
+
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	default:
+		return ERROR
+	}
+}
+
+// addAttr adds a to fields following the slog.Handler rules: empty attributes are ignored
+// and the attributes of a group with an empty key are inlined. nested tells whether fields
+// is inside a group, where errors are logged as their message.
+func addAttr(fields map[string]interface{}, a slog.Attr, nested bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		if nested {
+			fields[a.Key] = nestedValue(a.Value.Any())
+		} else {
+			fields[a.Key] = a.Value.Any()
+		}
+		return
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+	group := fields
+	if a.Key != "" {
+		group = groupAt(fields, []string{a.Key})
+		nested = true
+	}
+	for _, groupAttr := range attrs {
+		addAttr(group, groupAttr, nested)
+	}
+}
@@ -0,0 +1,100 @@
+//go:build go1.21
+// +build go1.21
+
+package golog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestSlogHandlerAttrs(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG)
+	slogger := slog.New(NewSlogHandler(logger)).With("service", "billing")
+
+	slogger.Info("charged", "amount", 42, slog.Bool("retried", false))
+
+	entry := decodeEntry(t, buf)
+	want := map[string]interface{}{
+		"message":  "charged",
+		"severity": "info",
+		"service":  "billing",
+		"amount":   float64(42),
+		"retried":  false,
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v", k, entry[k], v)
+		}
+	}
+}
+
+func TestSlogHandlerGroups(t *testing.T) {
+	logger, buf := newTestLogger(t, DEBUG)
+	slogger := slog.New(NewSlogHandler(logger)).
+		With("service", "billing", "failure", errors.New("degraded")).
+		WithGroup("request").
+		With("method", "GET", "cause", errors.New("retried"))
+
+	slogger.Info("handled",
+		"status", 200,
+		"err", errors.New("timeout"),
+		slog.Group("user", slog.String("id", "u1"), slog.Any("err", errors.New("locked"))),
+		slog.Group("", slog.String("inlined", "yes")),
+		slog.Group("empty"),
+	)
+
+	entry := decodeEntry(t, buf)
+	if entry["service"] != "billing" || entry["failure"] != "degraded" {
+		t.Errorf("service, failure = %v, %v, want billing, degraded", entry["service"], entry["failure"])
+	}
+	want := map[string]interface{}{
+		"method":  "GET",
+		"cause":   "retried",
+		"status":  float64(200),
+		"err":     "timeout",
+		"user":    map[string]interface{}{"id": "u1", "err": "locked"},
+		"inlined": "yes",
+	}
+	if got := entry["request"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("request = %v, want %v", got, want)
+	}
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug, "debug"},
+		{slog.LevelInfo, "info"},
+		{slog.LevelWarn, "warning"},
+		{slog.LevelError, "error"},
+		{slog.LevelError + 4, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			logger, buf := newTestLogger(t, DEBUG)
+			slog.New(NewSlogHandler(logger)).Log(context.Background(), tt.level, "msg")
+
+			if got := decodeEntry(t, buf)["severity"]; got != tt.want {
+				t.Errorf("severity = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	handler := NewSlogHandler(New(WARN, nil))
+
+	if handler.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("Enabled(info) = true for a warn logger")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelError) {
+		t.Errorf("Enabled(error) = false for a warn logger")
+	}
+}
@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// PanicKey holds the value recovered from a panic, see WithPanic.
+const PanicKey = "panic"
+
+// WithStack returns a new logger with the stack of the calling goroutine added under
+// StacktraceKey.
+func (l Logger) WithStack() Logger {
+	return l.WithFields(map[string]interface{}{StacktraceKey: string(stack())})
+}
+
+// WithPanic returns a new logger with the value recovered from a panic added under PanicKey,
+// and the panic message followed by the stack of the calling goroutine under StacktraceKey.
+// It is meant to be called from the deferred function that recovered.
+func (l Logger) WithPanic(recovered interface{}) Logger {
+	return l.WithFields(map[string]interface{}{
+		PanicKey:      fmt.Sprint(recovered),
+		StacktraceKey: fmt.Sprintf("panic: %v\n\n%s", recovered, stack()),
+	})
+}
+
+func stack() []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
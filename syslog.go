@@ -0,0 +1,66 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package golog
+
+import (
+	"io/ioutil"
+	"log/syslog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewSyslog creates a new logger sending its JSON entries to the syslog daemon at addr, see
+// syslog.Dial for network and addr. Each entry is sent with the syslog priority matching its
+// level. An error is returned if the connection to the daemon can't be established.
+func NewSyslog(l Level, network, addr, tag string) (Logger, error) {
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return Logger{}, err
+	}
+
+	options := newOptions(nil)
+	hook := &syslogHook{
+		writer:    writer,
+		formatter: newFormatter(options, ioutil.Discard),
+	}
+
+	return newOutputLogger(l, options, hook), nil
+}
+
+// syslogHook writes formatted entries to syslog, picking the priority from the entry level.
+type syslogHook struct {
+	writer    *syslog.Writer
+	formatter logrus.Formatter
+}
+
+func (h *syslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *syslogHook) Fire(entry *logrus.Entry) error {
+	serialized, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	msg := string(serialized)
+	switch entry.Level {
+	case logrus.DebugLevel, logrus.TraceLevel:
+		return h.writer.Debug(msg)
+	case logrus.InfoLevel:
+		return h.writer.Info(msg)
+	case logrus.WarnLevel:
+		return h.writer.Warning(msg)
+	case logrus.ErrorLevel:
+		return h.writer.Err(msg)
+	default:
+		return h.writer.Crit(msg)
+	}
+}
+
+func (h *syslogHook) Close() error {
+	return h.writer.Close()
+}
+
+func (h *syslogHook) output() {}
@@ -0,0 +1,27 @@
+package golog
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// A list of field keys used to correlate logs with OpenTelemetry traces
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// WithTraceContext returns a new logger with the trace and span IDs of the span active in
+// ctx added. The logger is returned unchanged when ctx carries no valid span context.
+func WithTraceContext(ctx context.Context, logger Logger) Logger {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return logger
+	}
+
+	return logger.WithFields(map[string]interface{}{
+		TraceIDKey: spanContext.TraceID().String(),
+		SpanIDKey:  spanContext.SpanID().String(),
+	})
+}
@@ -0,0 +1,51 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+)
+
+// Writer returns an io.Writer logging each line written to it as a message at the given
+// level, for instance to plug golog into http.Server.ErrorLog. A line is only logged once
+// its terminating newline has been written.
+func (l Logger) Writer(level Level) io.Writer {
+	return &levelWriter{
+		logger: l,
+		level:  level,
+	}
+}
+
+// StdLogger returns a standard library logger whose output is logged at the given level,
+// for code that still expects a *log.Logger. It has no prefix nor flags, golog takes care of
+// the formatting.
+func (l Logger) StdLogger(level Level) *log.Logger {
+	return log.New(l.Writer(level), "", 0)
+}
+
+type levelWriter struct {
+	logger Logger
+	level  Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimSuffix(w.buf[:i], []byte("\r"))
+		w.logger.Logln(w.level, string(line))
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,43 @@
+package golog
+
+import "testing"
+
+func TestStdLogger(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	logger = logger.WithFields(map[string]interface{}{"component": "legacy"})
+
+	logger.StdLogger(WARN).Printf("retrying in %ds", 5)
+
+	entry := decodeEntry(t, buf)
+	want := map[string]interface{}{
+		"message":   "retrying in 5s",
+		"severity":  "warning",
+		"component": "legacy",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v", k, entry[k], v)
+		}
+	}
+}
+
+func TestWriterBuffersPartialLines(t *testing.T) {
+	logger, buf := newTestLogger(t, INFO)
+	w := logger.Writer(INFO)
+
+	w.Write([]byte("first "))
+	if buf.Len() != 0 {
+		t.Fatalf("partial line logged: %q", buf.String())
+	}
+	w.Write([]byte("line\r\nsecond line\n"))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []string{"first line", "second line"} {
+		if entries[i]["message"] != want {
+			t.Errorf("entry %d message = %v, want %q", i, entries[i]["message"], want)
+		}
+	}
+}